@@ -0,0 +1,55 @@
+package main
+
+import "io"
+
+// ansiStripWriter removes ANSI escape sequences (colors, cursor movement) on
+// the way to the underlying writer, so the log file stays readable while the
+// console keeps its colors. Sequences split across writes are handled.
+type ansiStripWriter struct {
+	w     io.Writer
+	state int
+}
+
+const (
+	ansiText   = iota // Plain text
+	ansiEscape        // Just saw ESC
+	ansiCSI           // Inside "ESC [ ... final byte"
+)
+
+// newANSIStripWriter wraps w in an ansiStripWriter
+func newANSIStripWriter(w io.Writer) *ansiStripWriter {
+	return &ansiStripWriter{w: w}
+}
+
+// Write implements io.Writer. It reports len(p) on success even though
+// fewer bytes reach the underlying writer.
+func (a *ansiStripWriter) Write(p []byte) (int, error) {
+	plain := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch a.state {
+		case ansiText:
+			if b == 0x1b {
+				a.state = ansiEscape
+			} else {
+				plain = append(plain, b)
+			}
+		case ansiEscape:
+			if b == '[' {
+				a.state = ansiCSI
+			} else {
+				// Two-byte sequence such as "ESC c"
+				a.state = ansiText
+			}
+		case ansiCSI:
+			// Parameters and intermediates are 0x20-0x3F; the final byte ends it
+			if b >= 0x40 && b <= 0x7e {
+				a.state = ansiText
+			}
+		}
+	}
+
+	if _, err := a.w.Write(plain); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
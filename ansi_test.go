@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANSIStripWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"plain text", []string{"hello\n"}, "hello\n"},
+		{"color", []string{"\x1b[31mred\x1b[0m text"}, "red text"},
+		{"cursor movement", []string{"a\x1b[2Kb\x1b[1;5Hc"}, "abc"},
+		{"two-byte sequence", []string{"a\x1bcb"}, "ab"},
+		{"split after ESC", []string{"red\x1b", "[31m!"}, "red!"},
+		{"split inside CSI", []string{"\x1b[3", "1mred"}, "red"},
+		{"emoji kept", []string{"✅ Done"}, "✅ Done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newANSIStripWriter(&out)
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatalf("Write(%q) error: %v", s, err)
+				}
+				if n != len(s) {
+					t.Errorf("Write(%q) = %d, want %d", s, n, len(s))
+				}
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/window"
+)
+
+// clickReroll clicks a screen position in the reroll sequence. With
+// --background the click is posted to the game window hwnd instead, leaving
+// focus and the cursor alone.
+func clickReroll(cfg *Config, hwnd uintptr, x, y int) error {
+	if !cfg.Background {
+		return automation.Click(x, y)
+	}
+	clientX, clientY, err := window.ScreenToClient(hwnd, x, y)
+	if err != nil {
+		return err
+	}
+	return automation.ClickRerollBackground(hwnd, clientX, clientY)
+}
+
+// pressRerollKey presses a key in the reroll sequence, posting it to the
+// game window hwnd with --background
+func pressRerollKey(cfg *Config, hwnd uintptr, keyCode int) {
+	if !cfg.Background {
+		automation.PressKeyHold(keyCode, cfg.KeyHold.Duration())
+		return
+	}
+	if err := automation.PressKeyBackground(hwnd, keyCode, cfg.KeyHold.Duration()); err != nil {
+		fmt.Printf("⚠️ Key press failed: %v ", err)
+	}
+}
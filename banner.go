@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// String lists every resolved setting, one per line, starting with the
+// capture region, click offsets and the settings kept in the internal
+// packages. It walks the struct so new fields show up without having to be
+// added here.
+func (c *Config) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-18s %dx%d at (%d,%d)\n", "CaptureRegion:", CAPTURE_WIDTH, CAPTURE_HEIGHT, CAPTURE_X, CAPTURE_Y)
+	fmt.Fprintf(&b, "  %-18s (%d,%d)\n", "ClickOffset:", CLICK_OFFSET_X, CLICK_OFFSET_Y)
+	for _, setting := range packageSettings() {
+		fmt.Fprintf(&b, "  %-18s %s\n", setting[0]+":", setting[1])
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fmt.Fprintf(&b, "  %-18s %s\n", t.Field(i).Name+":", configValue(v.Field(i)))
+	}
+	return b.String()
+}
+
+// packageSettings are the name/value pairs of the settings main hands to the
+// internal packages through their setters, which Config doesn't hold
+func packageSettings() [][2]string {
+	tessdata, tessConfig := ocr.TesseractOptions()
+	refWidth, refHeight := window.ReferenceSize()
+
+	refResolution := "off"
+	if refWidth > 0 && refHeight > 0 {
+		refResolution = fmt.Sprintf("%dx%d", refWidth, refHeight)
+	}
+	dpiScale := "off"
+	if scale := automation.CursorScale(); scale != 1 {
+		dpiScale = fmt.Sprintf("%.2f", scale)
+	}
+
+	return [][2]string{
+		{"WindowTitle", window.TargetTitle()},
+		{"Anchor", window.CurrentAnchor().String()},
+		{"OutputDir", screenshot.OutputDir()},
+		{"StopKeys", automation.StopKeyNames()},
+		{"TessdataDir", orDefault(tessdata)},
+		{"TessConfig", orDefault(tessConfig)},
+		{"DPIScale", dpiScale},
+		{"RefResolution", refResolution},
+	}
+}
+
+// orDefault shows an unset string setting as "default"
+func orDefault(s string) string {
+	if s == "" {
+		return "default"
+	}
+	return s
+}
+
+// configValue formats one Config field for the banner: "off" for unset
+// options, String() where the type has one, a count for maps (which can be
+// long, like the panic allowlist) and the plain value otherwise
+func configValue(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		if field.IsNil() {
+			return "off"
+		}
+	}
+
+	if stringer, ok := field.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		return "on"
+	case reflect.Map:
+		return fmt.Sprintf("%d entries", field.Len())
+	case reflect.Interface:
+		return fmt.Sprintf("%T", field.Interface())
+	}
+	return fmt.Sprintf("%v", field.Interface())
+}
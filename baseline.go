@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+const (
+	baselinePixelTolerance = 24  // Per-channel difference ignored as noise
+	baselineInkThreshold   = 128 // Brightness above which a pixel counts as text
+	baselineAlignSlack     = 5   // Pixels the text regions may be offset and still align
+)
+
+// runBaselineCheck captures the configured region once and compares it with
+// a known-good reference screenshot
+func runBaselineCheck(referencePath string, cfg *Config) {
+	fmt.Println("🔍 BASELINE CHECK")
+
+	reference, err := screenshot.LoadImage(referencePath)
+	if err != nil {
+		fmt.Printf("❌ Could not load reference image: %v\n", err)
+		return
+	}
+
+	fmt.Print("Finding MapleStory window... ")
+	windowRect, err := window.GetMaplestoryWindowRect()
+	if err != nil {
+		fmt.Printf("❌ Failed: %v\n", err)
+		fmt.Println("Make sure MapleStory is running and visible.")
+		return
+	}
+	fmt.Println("✅ Found!")
+
+	fmt.Print("Capturing... ")
+	img, err := screenshot.CaptureRegion(windowRect, statRegion(windowRect))
+	if err != nil {
+		fmt.Printf("❌ Screenshot failed: %v\n", err)
+		return
+	}
+	img = screenshot.Crop(img, cfg.CropMargins)
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "baseline", 1)
+	if err != nil {
+		fmt.Printf("❌ Save failed: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Saved: %s\n", filename)
+
+	diff, err := screenshot.DiffPercent(img, reference, baselinePixelTolerance)
+	if err != nil {
+		fmt.Printf("❌ Cannot compare: %v\n", err)
+		fmt.Println("The reference must be captured with the same region size and crop margins.")
+		return
+	}
+	fmt.Printf("Pixel difference: %.1f%%\n", diff)
+
+	captureText := screenshot.TextBounds(img, baselineInkThreshold)
+	referenceText := screenshot.TextBounds(reference, baselineInkThreshold)
+	fmt.Printf("Text region (capture):   %v\n", captureText)
+	fmt.Printf("Text region (reference): %v\n", referenceText)
+
+	if captureText.Empty() || referenceText.Empty() {
+		fmt.Println("❌ No text found in one of the images - the region is probably off")
+		return
+	}
+
+	offsetX := captureText.Min.X - referenceText.Min.X
+	offsetY := captureText.Min.Y - referenceText.Min.Y
+	if abs(offsetX) <= baselineAlignSlack && abs(offsetY) <= baselineAlignSlack {
+		fmt.Printf("✅ Text regions align (offset %d,%d)\n", offsetX, offsetY)
+	} else {
+		fmt.Printf("❌ Text regions are offset by (%d,%d) - adjust the capture offsets by that amount\n", offsetX, offsetY)
+	}
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
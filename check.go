@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// blankThreshold is the brightness below which a whole capture counts as black
+const blankThreshold = 16
+
+// checkResult is the outcome of one setup check
+type checkResult struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// checkTesseract verifies the tesseract binary is on PATH
+func checkTesseract() checkResult {
+	path, err := exec.LookPath("tesseract")
+	if err != nil {
+		return checkResult{Name: "Tesseract installed", Detail: "tesseract not found in PATH"}
+	}
+	return checkResult{Name: "Tesseract installed", OK: true, Detail: path}
+}
+
+// checkWindow verifies the game window can be found
+func checkWindow() (checkResult, *window.WindowRect) {
+	windowRect, err := window.GetMaplestoryWindowRect()
+	if err != nil {
+		return checkResult{Name: "Window found", Detail: err.Error()}, nil
+	}
+	detail := fmt.Sprintf("%dx%d at (%d,%d)", windowRect.Right-windowRect.Left, windowRect.Bottom-windowRect.Top, windowRect.Left, windowRect.Top)
+	return checkResult{Name: "Window found", OK: true, Detail: detail}, windowRect
+}
+
+// checkCaptureRegion verifies the capture region fits inside the window
+func checkCaptureRegion(windowRect *window.WindowRect) checkResult {
+	region := statRegion(windowRect)
+	if _, _, err := window.AbsoluteClickPos(windowRect, region.X, region.Y); err != nil {
+		return checkResult{Name: "Capture region in window", Detail: err.Error()}
+	}
+	if _, _, err := window.AbsoluteClickPos(windowRect, region.X+region.Width-1, region.Y+region.Height-1); err != nil {
+		return checkResult{Name: "Capture region in window", Detail: err.Error()}
+	}
+	return checkResult{Name: "Capture region in window", OK: true}
+}
+
+// checkCaptureContent captures the region once and verifies it isn't black
+// and that OCR reads flame-like text from it
+func checkCaptureContent(windowRect *window.WindowRect, cfg *Config) checkResult {
+	img, err := screenshot.CaptureRegion(windowRect, statRegion(windowRect))
+	if err != nil {
+		return checkResult{Name: "Capture shows flame stats", Detail: err.Error()}
+	}
+	img = screenshot.Crop(img, cfg.CropMargins)
+
+	if screenshot.IsBlankRegion(img, blankThreshold) {
+		return checkResult{Name: "Capture shows flame stats", Detail: "captured region is black (window hidden or minimized?)"}
+	}
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "check", 1)
+	if err != nil {
+		return checkResult{Name: "Capture shows flame stats", Detail: err.Error()}
+	}
+
+	text, err := ocr.ExtractText(filename)
+	if err != nil {
+		return checkResult{Name: "Capture shows flame stats", Detail: err.Error()}
+	}
+	text = ocr.NormalizeStatText(text, cfg.StatAliases)
+	if !validateFlameText(text) {
+		return checkResult{Name: "Capture shows flame stats", Detail: fmt.Sprintf("no stat names in OCR text %q (see %s)", strings.TrimSpace(text), filename)}
+	}
+
+	return checkResult{Name: "Capture shows flame stats", OK: true, Detail: filename}
+}
+
+// checkClickTarget verifies the reroll click lands inside the window
+func checkClickTarget(windowRect *window.WindowRect) checkResult {
+	offsetX, offsetY := clickOffset(windowRect)
+	x, y, err := window.AbsoluteClickPos(windowRect, offsetX, offsetY)
+	if err != nil {
+		return checkResult{Name: "Reroll click in window", Detail: err.Error()}
+	}
+	return checkResult{Name: "Reroll click in window", OK: true, Detail: fmt.Sprintf("(%d,%d)", x, y)}
+}
+
+// runSetupCheck runs every setup check, prints a pass/fail report and
+// returns whether all of them passed
+func runSetupCheck(cfg *Config) bool {
+	fmt.Println("🩺 SETUP CHECK")
+
+	results := []checkResult{checkTesseract()}
+
+	windowResult, windowRect := checkWindow()
+	results = append(results, windowResult)
+	if windowRect != nil {
+		results = append(results,
+			checkCaptureRegion(windowRect),
+			checkCaptureContent(windowRect, cfg),
+			checkClickTarget(windowRect),
+		)
+	}
+
+	allOK := true
+	for _, result := range results {
+		status := "✅ PASS"
+		if !result.OK {
+			status = "❌ FAIL"
+			allOK = false
+		}
+		if result.Detail != "" {
+			fmt.Printf("%s  %s - %s\n", status, result.Name, result.Detail)
+		} else {
+			fmt.Printf("%s  %s\n", status, result.Name)
+		}
+	}
+
+	if allOK {
+		fmt.Println("\n🎉 Setup looks good!")
+	} else {
+		fmt.Println("\n⚠️ Some checks failed - fix them before starting a session")
+	}
+	return allOK
+}
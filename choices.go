@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// choiceScore is the score of one stat box in a multi-choice reroll UI
+type choiceScore struct {
+	Region screenshot.Region
+	Text   string
+	Score  int
+}
+
+// parseChoiceRegions parses a ";"-separated list of "x,y,width,height" regions
+func parseChoiceRegions(spec string) ([]screenshot.Region, error) {
+	var regions []screenshot.Region
+	for _, part := range strings.Split(spec, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		region, err := screenshot.ParseRegion(part)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	if len(regions) < 2 {
+		return nil, fmt.Errorf("need at least two choice regions separated by ';', got %q", spec)
+	}
+	return regions, nil
+}
+
+// bestChoice returns the index of the highest-scoring choice; ties go to the
+// first one. It returns -1 for no choices, and when every choice scored 0,
+// since that means nothing was read (a closed UI), not that choice 1 won.
+func bestChoice(scores []choiceScore) int {
+	best := -1
+	for i, s := range scores {
+		if best < 0 || s.Score > scores[best].Score {
+			best = i
+		}
+	}
+	if best >= 0 && scores[best].Score == 0 {
+		return -1
+	}
+	return best
+}
+
+// runChoices captures every choice region, scores each for target (a main
+// stat or weapon type) and reports the best one, clicking it if asked
+func runChoices(spec, target string, click bool, cfg *Config) {
+	fmt.Println("🗂️  MULTI-CHOICE")
+
+	regions, err := parseChoiceRegions(spec)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	fmt.Print("Finding MapleStory window... ")
+	windowRect, err := window.GetMaplestoryWindowRect()
+	if err != nil {
+		fmt.Printf("❌ Failed: %v\n", err)
+		return
+	}
+	fmt.Println("✅ Found!")
+	applyAutoDPIScale(cfg)
+
+	scores := make([]choiceScore, 0, len(regions))
+	for i, region := range regions {
+		img, err := screenshot.CaptureRegion(windowRect, region)
+		if err != nil {
+			fmt.Printf("❌ Choice %d: screenshot failed: %v\n", i+1, err)
+			return
+		}
+		filename, err := screenshot.SaveDebugImageWithPrefix(screenshot.Crop(img, cfg.CropMargins), "choice", i+1)
+		if err != nil {
+			fmt.Printf("❌ Choice %d: save failed: %v\n", i+1, err)
+			return
+		}
+		text, err := ocr.ExtractText(filename)
+		if err != nil {
+			fmt.Printf("❌ Choice %d: OCR failed: %v\n", i+1, err)
+			return
+		}
+		text = ocr.NormalizeStatText(text, cfg.StatAliases)
+
+		score, err := scoreTarget(text, target, cfg)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		scores = append(scores, choiceScore{Region: region, Text: strings.TrimSpace(text), Score: score})
+	}
+
+	best := bestChoice(scores)
+	fmt.Printf("%-7s %-20s %s\n", "CHOICE", "REGION", "LINES")
+	for i, s := range scores {
+		marker := ""
+		if i == best {
+			marker = "  ⭐ best"
+		}
+		fmt.Printf("%-7d %-20s %d%s\n", i+1, s.Region, s.Score, marker)
+	}
+
+	if best < 0 {
+		fmt.Println("⚠️ Every choice scored 0 - the stat boxes may be closed or misplaced, so no choice is best")
+		return
+	}
+
+	if !click {
+		return
+	}
+
+	region := scores[best].Region
+	clickX, clickY, err := window.AbsoluteClickPos(windowRect, region.X+region.Width/2, region.Y+region.Height/2)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if _, err := window.FindAndActivateMaplestory(); err != nil {
+		fmt.Printf("❌ Could not activate MapleStory: %v\n", err)
+		return
+	}
+	cfg.clock().Sleep(cfg.ActivateDelay)
+
+	if err := automation.Click(clickX, clickY); err != nil {
+		fmt.Printf("❌ Click failed: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Clicked choice %d at (%d,%d)\n", best+1, clickX, clickY)
+}
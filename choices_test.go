@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestBestChoice(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []int
+		want   int
+	}{
+		{"no choices", nil, -1},
+		{"clear winner", []int{3, 7, 5}, 1},
+		{"ties go to the first", []int{2, 6, 6}, 1},
+		{"single choice", []int{4}, 0},
+		// Every box reading zero means nothing was read, not that choice 1 won
+		{"all zero", []int{0, 0, 0}, -1},
+		{"single zero", []int{0}, -1},
+		{"zero beside a real score", []int{0, 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := make([]choiceScore, len(tt.scores))
+			for i, s := range tt.scores {
+				scores[i] = choiceScore{Score: s}
+			}
+			if got := bestChoice(scores); got != tt.want {
+				t.Errorf("bestChoice(%v) = %d, want %d", tt.scores, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// classProfile says which stats matter to a class and how much each is worth,
+// in main stat points. Names are the ones used by --stop-expr.
+type classProfile struct {
+	Name    string
+	Weights map[string]float64
+}
+
+// classProfiles are the built-in --class profiles. Secondary stats are worth
+// a tenth of the main stat, 1 ATT/MATT about 3 and 1% All Stats about 10.
+var classProfiles = map[string]classProfile{
+	"warrior": {Name: "warrior", Weights: map[string]float64{"STR": 1, "DEX": 0.1, "ATT": 3, "ALLSTAT": 10}},
+	"bowman":  {Name: "bowman", Weights: map[string]float64{"DEX": 1, "STR": 0.1, "ATT": 3, "ALLSTAT": 10}},
+	"mage":    {Name: "mage", Weights: map[string]float64{"INT": 1, "LUK": 0.1, "MATT": 3, "ALLSTAT": 10}},
+	"thief":   {Name: "thief", Weights: map[string]float64{"LUK": 1, "DEX": 0.1, "ATT": 3, "ALLSTAT": 10}},
+	"pirate":  {Name: "pirate", Weights: map[string]float64{"STR": 1, "DEX": 1, "ATT": 3, "ALLSTAT": 10}},
+	// Xenon uses STR, DEX and LUK equally, so All Stats counts three times over
+	"xenon": {Name: "xenon", Weights: map[string]float64{"STR": 1, "DEX": 1, "LUK": 1, "ATT": 3, "ALLSTAT": 30}},
+}
+
+// parseClassProfile looks up a built-in profile by name
+func parseClassProfile(name string) (*classProfile, error) {
+	profile, ok := classProfiles[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		names := make([]string, 0, len(classProfiles))
+		for n := range classProfiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown class: %s (valid options: %s)", name, strings.Join(names, ", "))
+	}
+	return &profile, nil
+}
+
+// Score adds up the weighted stat values read from the text
+func (p *classProfile) Score(text string) int {
+	total := 0.0
+	for name, value := range stopExprValues(text, 0) {
+		total += p.Weights[name] * value
+	}
+	return int(total)
+}
+
+// String lists the profile's stats and weights, e.g. "xenon (ALLSTAT x30, ATT x3, ...)"
+func (p *classProfile) String() string {
+	names := make([]string, 0, len(p.Weights))
+	for name := range p.Weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s x%g", name, p.Weights[name]))
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, strings.Join(parts, ", "))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/window"
+)
+
+// Clock is the source of time for the reroll loop and everything it calls,
+// so timing-dependent logic can be driven without real waits
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock is the Clock backed by the time package
+type realClock struct{}
+
+// Now implements Clock
+func (realClock) Now() time.Time { return time.Now() }
+
+// Sleep implements Clock
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// shareClock makes the waits inside the internal packages (click and key
+// steps, window polling) follow clock too
+func shareClock(clock Clock) {
+	automation.SetSleep(clock.Sleep)
+	window.SetClock(clock.Now, clock.Sleep)
+}
+
+// clock returns the configured Clock, or the real one when none is set
+func (c *Config) clock() Clock {
+	if c.Clock == nil {
+		return realClock{}
+	}
+	return c.Clock
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+// fakeClock is a Clock whose Sleep returns at once, moving its time forward
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now implements Clock
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep implements Clock
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.slept += d
+}
+
+// Slept returns the total time slept
+func (c *fakeClock) Slept() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.slept
+}
+
+func TestRerollLoopFollowsClock(t *testing.T) {
+	defer screenshot.SetOutputDir(screenshot.OutputDir())
+	screenshot.SetOutputDir(t.TempDir())
+
+	const interval = 10 * time.Second
+	texts := []string{
+		"DEF +120\nMax HP +3%\n",
+		"STR +9%\nMax HP +3%\n",
+		"STR +12%\nAll Stats +6%\n",
+	}
+	reads := 0
+
+	clock := newFakeClock()
+	frame := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	cfg := &Config{
+		Clock:         clock,
+		Frames:        &demoFrames{dir: "test", frames: []*image.RGBA{frame}, interval: interval, clock: clock},
+		RequiredLines: 2,
+		OCR: func(string) (string, error) {
+			text := texts[reads%len(texts)]
+			reads++
+			return text, nil
+		},
+	}
+	mode := rerollMode{
+		Name: "armor",
+		Evaluate: func(text string) (int, bool) {
+			count := countMainStatLines(text, STR, 0)
+			return count, enoughLines(count, cfg)
+		},
+		Describe:       func(score int) string { return "" },
+		SuccessMessage: func(score int) string { return "" },
+	}
+
+	start := time.Now()
+	runRerollLoop(nil, mode, cfg)
+
+	if reads != len(texts) {
+		t.Errorf("loop read %d frames, want %d", reads, len(texts))
+	}
+	if got, want := clock.Slept(), 2*interval; got != want {
+		t.Errorf("fake clock slept %s, want %s", got, want)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("loop took %s of real time, want it to follow the fake clock", elapsed)
+	}
+}
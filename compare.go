@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// configScore is the score of one text under one target config
+type configScore struct {
+	Config  string
+	Score   int
+	Success bool
+}
+
+// scoreTarget scores text for one target: a main stat (STR/DEX/INT/LUK,
+// armor rules) or a weapon type (ATT/MATT, weapon rules). Only lines that
+// pass --min-line-value count.
+func scoreTarget(text, target string, cfg *Config) (int, error) {
+	target = strings.ToUpper(strings.TrimSpace(target))
+	if target == "ATT" || target == "MATT" {
+		return countWeaponStatLines(text, target, cfg.MinLineValue), nil
+	}
+	mainStat, err := parseMainStat(target)
+	if err != nil {
+		return 0, fmt.Errorf("invalid config %q (valid options: STR, DEX, INT, LUK, ATT, MATT)", target)
+	}
+	return countMainStatLines(text, mainStat, cfg.MinLineValue), nil
+}
+
+// compareConfigs scores the same OCR text for each target config, judging
+// each by the run's line-count rule
+func compareConfigs(text string, targets []string, cfg *Config) ([]configScore, error) {
+	scores := make([]configScore, 0, len(targets))
+	for _, target := range targets {
+		target = strings.ToUpper(strings.TrimSpace(target))
+		if target == "" {
+			continue
+		}
+
+		score, err := scoreTarget(text, target, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		scores = append(scores, configScore{Config: target, Score: score, Success: enoughLines(score, cfg)})
+	}
+	return scores, nil
+}
+
+// runCompareConfigs reads one capture (from imagePath, or live when empty)
+// and prints how it scores under each target config
+func runCompareConfigs(spec, imagePath string, cfg *Config) {
+	fmt.Println("⚖️  COMPARE CONFIGS")
+
+	if imagePath == "" {
+		fmt.Print("Finding MapleStory window... ")
+		windowRect, err := window.GetMaplestoryWindowRect()
+		if err != nil {
+			fmt.Printf("❌ Failed: %v\n", err)
+			return
+		}
+		fmt.Println("✅ Found!")
+
+		img, err := screenshot.CaptureRegion(windowRect, statRegion(windowRect))
+		if err != nil {
+			fmt.Printf("❌ Screenshot failed: %v\n", err)
+			return
+		}
+		imagePath, err = screenshot.SaveDebugImageWithPrefix(screenshot.Crop(img, cfg.CropMargins), "compare", 1)
+		if err != nil {
+			fmt.Printf("❌ Save failed: %v\n", err)
+			return
+		}
+	}
+
+	text, err := ocr.ExtractText(imagePath)
+	if err != nil {
+		fmt.Printf("❌ OCR failed: %v\n", err)
+		return
+	}
+	text = ocr.NormalizeStatText(text, cfg.StatAliases)
+	fmt.Printf("Text extracted from %s:\n%s\n", imagePath, text)
+
+	scores, err := compareConfigs(text, strings.Split(spec, ","), cfg)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("%-8s %-6s %s\n", "CONFIG", "LINES", fmt.Sprintf("GOOD (%d+)", cfg.RequiredLines))
+	for _, s := range scores {
+		good := "no"
+		if s.Success {
+			good = "yes"
+		}
+		fmt.Printf("%-8s %-6d %s\n", s.Config, s.Score, good)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+const (
+	maxConfirmDialogs     = 2                      // Confirmation dialogs answered per reroll
+	dialogPollInterval    = 100 * time.Millisecond // Time between dialog checks
+	dialogFollowupTimeout = 500 * time.Millisecond // How long to wait for a second dialog
+)
+
+// pollForDialog calls visible every interval until it reports a dialog or the
+// timeout elapses. now and sleep are injectable so the timing can be faked.
+func pollForDialog(visible func() bool, timeout, interval time.Duration, now func() time.Time, sleep func(time.Duration)) bool {
+	deadline := now().Add(timeout)
+	for {
+		if visible() {
+			return true
+		}
+		if !now().Before(deadline) {
+			return false
+		}
+		sleep(interval)
+	}
+}
+
+// dialogVisible reports whether OCR finds any text in the confirmation region
+func dialogVisible(handle *window.WindowHandle, region screenshot.Region) bool {
+	img, err := screenshot.CaptureHandleRegion(handle, region)
+	if err != nil {
+		return false
+	}
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "confirm", 1)
+	if err != nil {
+		return false
+	}
+
+	text, err := ocr.ExtractText(filename)
+	return err == nil && strings.TrimSpace(text) != ""
+}
+
+// confirmDialogs presses Enter once for each confirmation dialog that shows up
+// in the configured region, instead of pressing Enter blindly
+func confirmDialogs(handle *window.WindowHandle, cfg *Config) {
+	visible := func() bool { return dialogVisible(handle, cfg.ConfirmRegion) }
+	press := func() { pressRerollKey(cfg, handle.HWND, automation.VK_RETURN) }
+	if answerDialogs(visible, press, cfg.ConfirmTimeout, cfg.clock()) == 0 {
+		fmt.Print("⚠️ No confirmation dialog appeared... ")
+	}
+}
+
+// answerDialogs waits up to timeout for the first dialog and
+// dialogFollowupTimeout for each one after it, calling press for every
+// dialog seen, and returns how many were answered
+func answerDialogs(visible func() bool, press func(), timeout time.Duration, clock Clock) int {
+	for i := 0; i < maxConfirmDialogs; i++ {
+		if i > 0 {
+			timeout = dialogFollowupTimeout
+		}
+
+		if !pollForDialog(visible, timeout, dialogPollInterval, clock.Now, clock.Sleep) {
+			return i
+		}
+
+		fmt.Printf("Enter%d... ", i+1)
+		press()
+		clock.Sleep(dialogPollInterval)
+	}
+	return maxConfirmDialogs
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// scriptedVisible returns a dialog check that answers from script in turn,
+// repeating the last answer, and counts its calls
+func scriptedVisible(script ...bool) (func() bool, *int) {
+	calls := 0
+	return func() bool {
+		answer := script[min(calls, len(script)-1)]
+		calls++
+		return answer
+	}, &calls
+}
+
+func TestPollForDialog(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    []bool
+		timeout   time.Duration
+		want      bool
+		wantCalls int
+		wantSlept time.Duration
+	}{
+		{"already open", []bool{true}, time.Second, true, 1, 0},
+		{"opens on the fourth check", []bool{false, false, false, true}, time.Second, true, 4, 300 * time.Millisecond},
+		{"never opens", []bool{false}, time.Second, false, 11, time.Second},
+		{"opens too late", []bool{false, false, false, true}, 200 * time.Millisecond, false, 3, 200 * time.Millisecond},
+		{"zero timeout checks once", []bool{false}, 0, false, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			visible, calls := scriptedVisible(tt.script...)
+			got := pollForDialog(visible, tt.timeout, dialogPollInterval, clock.Now, clock.Sleep)
+			if got != tt.want {
+				t.Errorf("pollForDialog() = %v, want %v", got, tt.want)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("dialog checked %d times, want %d", *calls, tt.wantCalls)
+			}
+			if clock.Slept() != tt.wantSlept {
+				t.Errorf("slept %v, want %v", clock.Slept(), tt.wantSlept)
+			}
+		})
+	}
+}
+
+func TestAnswerDialogs(t *testing.T) {
+	// Checks that fit in the follow-up wait, plus one
+	lateChecks := int(dialogFollowupTimeout/dialogPollInterval) + 2
+
+	tests := []struct {
+		name   string
+		script []bool
+		want   int
+	}{
+		{"no dialog", []bool{false}, 0},
+		{"one dialog", []bool{true, false}, 1},
+		{"slow first dialog", []bool{false, false, false, true, false}, 1},
+		{"second dialog follows", []bool{true, false, true, false}, 2},
+		{"never more than the maximum", []bool{true}, maxConfirmDialogs},
+		{"second dialog too late", append(append([]bool{true}, make([]bool, lateChecks)...), true), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visible, _ := scriptedVisible(tt.script...)
+			presses := 0
+			got := answerDialogs(visible, func() { presses++ }, time.Second, newFakeClock())
+			if got != tt.want || presses != tt.want {
+				t.Errorf("answerDialogs() = %d with %d presses, want %d", got, presses, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"maple_flame/internal/ocr"
+)
+
+// Decider decides whether the current roll is good enough to stop on.
+// history holds the earlier attempts, oldest first, not including this one.
+// reason explains the decision when there is something worth telling the
+// user; it may be empty.
+type Decider interface {
+	ShouldStop(text string, score int, history []attemptResult) (stop bool, reason string)
+}
+
+// modeDecider is the built-in stop rule: the mode's own rule (or
+// --target-score when set), plus any extra requirement the mode has
+type modeDecider struct {
+	mode        rerollMode
+	targetScore int
+}
+
+// ShouldStop implements Decider
+func (d modeDecider) ShouldStop(text string, score int, history []attemptResult) (bool, string) {
+	_, success := d.mode.Evaluate(text)
+
+	// An explicit target score takes precedence over the mode's own rule,
+	// whatever the mode scores: lines, a stat sum or a class score
+	if d.targetScore > 0 {
+		success = score >= d.targetScore
+	}
+
+	// Extra mode requirements apply whichever rule produced the success
+	if success && d.mode.Qualifies != nil {
+		if ok, reason := d.mode.Qualifies(text); !ok {
+			return false, "Score reached but " + reason
+		}
+	}
+
+	return success, ""
+}
+
+// exprDecider stops when a --stop-expr expression matches the stats read.
+// The mode's extra requirements (e.g. --prime-main) still apply to a match.
+type exprDecider struct {
+	expr      *stopExpr
+	qualifies func(text string) (ok bool, reason string)
+}
+
+// ShouldStop implements Decider
+func (d exprDecider) ShouldStop(text string, score int, history []attemptResult) (bool, string) {
+	if !d.expr.Matches(stopExprValues(text, score)) {
+		return false, ""
+	}
+	if d.qualifies != nil {
+		if ok, reason := d.qualifies(text); !ok {
+			return false, "Stop expression matched but " + reason
+		}
+	}
+	return true, "stop expression matched: " + d.expr.String()
+}
+
+// statCaps maps stop expression stat names to the highest value a roll can give
+type statCaps map[string]int
+
+// parseStatCaps parses a "STAT=MAX,STAT=MAX" list such as "STR=48,ALLSTAT=6"
+func parseStatCaps(spec string) (statCaps, error) {
+	caps := statCaps{}
+	for _, part := range strings.Split(spec, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !ok || !stopExprVars[name] || name == "SCORE" {
+			return nil, fmt.Errorf("invalid stat cap %q (expected STAT=MAX, e.g. STR=48)", part)
+		}
+		max, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || max <= 0 {
+			return nil, fmt.Errorf("invalid stat cap %q: max must be a positive number", part)
+		}
+		caps[name] = max
+	}
+	return caps, nil
+}
+
+// Reached returns the first stat (alphabetically) with a single line at or
+// above its cap. Caps are per line, so two lines of the same stat or a flat
+// All Stats line don't add up to one.
+func (c statCaps) Reached(text string) (string, bool) {
+	values := statLineMax(text)
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if values[name] >= float64(c[name]) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// statLineMax returns the highest value read on any single line of each
+// stat, using the stop expression names. Flat and percent All Stats lines
+// go to ALLSTATFLAT and ALLSTAT.
+func statLineMax(text string) map[string]float64 {
+	values := map[string]float64{}
+	for _, line := range strings.Split(text, "\n") {
+		name := stopExprStatName(line)
+		if name == "" {
+			continue
+		}
+		value, percent, ok := ocr.ExtractStatValue(line)
+		if !ok {
+			continue
+		}
+		if name == "ALLSTAT" && !percent {
+			name = "ALLSTATFLAT"
+		}
+		values[name] = max(values[name], float64(value))
+	}
+	return values
+}
+
+// capDecider stops as soon as any stat hits its cap, since no reroll can do
+// better on that stat, and otherwise defers to the next decider. The mode's
+// extra requirements (e.g. --prime-main) still apply to a capped roll.
+type capDecider struct {
+	caps      statCaps
+	qualifies func(text string) (ok bool, reason string)
+	next      Decider
+}
+
+// ShouldStop implements Decider
+func (d capDecider) ShouldStop(text string, score int, history []attemptResult) (bool, string) {
+	if name, ok := d.caps.Reached(text); ok {
+		if d.qualifies != nil {
+			if ok, reason := d.qualifies(text); !ok {
+				return false, fmt.Sprintf("%s reached its cap but %s", name, reason)
+			}
+		}
+		return true, fmt.Sprintf("%s reached its cap of %d", name, d.caps[name])
+	}
+	return d.next.ShouldStop(text, score, history)
+}
+
+// newDecider picks the stop rule for a run: the --stop-expr expression when
+// one was given, otherwise the mode's built-in rule, either way stopping
+// early on a --stat-caps cap
+func newDecider(mode rerollMode, cfg *Config) Decider {
+	var decider Decider = modeDecider{mode: mode, targetScore: cfg.TargetScore}
+	if cfg.StopExpr != nil {
+		decider = exprDecider{expr: cfg.StopExpr, qualifies: mode.Qualifies}
+	}
+	if len(cfg.StatCaps) > 0 {
+		decider = capDecider{caps: cfg.StatCaps, qualifies: mode.Qualifies, next: decider}
+	}
+	return decider
+}
+
+// stopExprValues reads the stat values in the text into the names used by
+// stop expressions. Repeated stats are summed. ALLSTAT is the All Stats
+// percentage; a flat All Stats line goes to ALLSTATFLAT and is also added to
+// each main stat, since that is what it raises in game.
+func stopExprValues(text string, score int) map[string]float64 {
+	vars := map[string]float64{"SCORE": float64(score)}
+	for _, line := range strings.Split(text, "\n") {
+		name := stopExprStatName(line)
+		if name == "" {
+			continue
+		}
+		value, percent, ok := ocr.ExtractStatValue(line)
+		if !ok {
+			continue
+		}
+		if name == "ALLSTAT" && !percent {
+			vars["ALLSTATFLAT"] += float64(value)
+			for _, stat := range []string{"STR", "DEX", "INT", "LUK"} {
+				vars[stat] += float64(value)
+			}
+			continue
+		}
+		vars[name] += float64(value)
+	}
+	return vars
+}
+
+// stopExprStatName maps a stat line to its stop expression name, or "" if
+// the line isn't a stat expressions can refer to
+func stopExprStatName(line string) string {
+	upperLine := strings.ToUpper(line)
+
+	// Order matters: "Magic ATT" contains "ATT", "Boss Monster Damage" contains "DAMAGE"
+	switch {
+	case isAllStatLine(line):
+		return "ALLSTAT"
+	case strings.Contains(upperLine, "MAGIC ATT"):
+		return "MATT"
+	case strings.Contains(upperLine, "ATT"):
+		return "ATT"
+	case strings.Contains(upperLine, "BOSS"):
+		return "BOSS"
+	case strings.Contains(upperLine, "IGNORE"), isIgnoreDefenseLine(line):
+		return "IED"
+	case strings.Contains(upperLine, "DAMAGE"):
+		return "DAMAGE"
+	case strings.Contains(upperLine, "MAX HP"):
+		return "HP"
+	case strings.Contains(upperLine, "MAX MP"):
+		return "MP"
+	}
+
+	for _, stat := range []string{"STR", "DEX", "INT", "LUK"} {
+		if strings.Contains(upperLine, stat) {
+			return stat
+		}
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestModeDeciderTargetScore(t *testing.T) {
+	const target = 180
+
+	tests := []struct {
+		name      string
+		score     int
+		modeStops bool // what the mode's own rule (--max-lines, --min-stat-sum) says
+		want      bool
+	}{
+		{"one below", target - 1, false, false},
+		{"one below, mode rule met", target - 1, true, false},
+		{"exactly", target, false, true},
+		{"one above", target + 1, false, true},
+		{"one above, mode rule met", target + 1, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := rerollMode{
+				Evaluate: func(text string) (int, bool) { return tt.score, tt.modeStops },
+			}
+			cfg := &Config{TargetScore: target}
+			stop, _ := newDecider(mode, cfg).ShouldStop("", tt.score, nil)
+			if stop != tt.want {
+				t.Errorf("ShouldStop(score %d) = %v, want %v", tt.score, stop, tt.want)
+			}
+		})
+	}
+}
+
+func TestModeDeciderTargetScoreAcrossModes(t *testing.T) {
+	text := "STR +12%\nAll Stats +6%\nDEX +9%\n"
+
+	tests := []struct {
+		name   string
+		cfg    *Config
+		target int
+		want   bool
+	}{
+		// Two STR lines (STR and All Stats): the line count is the score
+		{"lines at target", &Config{RequiredLines: 5}, 2, true},
+		{"lines below target", &Config{RequiredLines: 1}, 3, false},
+		// 12 + 6 x 10 = 72: the stat sum is the score
+		{"stat sum at target", &Config{MinStatSum: 500, AllStatWeight: 10}, 72, true},
+		{"stat sum below target", &Config{MinStatSum: 10, AllStatWeight: 10}, 73, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.TargetScore = tt.target
+			mode := rerollMode{
+				Evaluate: func(text string) (int, bool) {
+					if cfg.MinStatSum > 0 {
+						mainStatTotal, allStatPercent := sumMainStatValues(text, STR)
+						sum := statSum(mainStatTotal, allStatPercent, cfg.AllStatWeight)
+						return sum, sum >= cfg.MinStatSum
+					}
+					count := countMainStatLines(text, STR, cfg.MinLineValue)
+					return count, enoughLines(count, cfg)
+				},
+			}
+			score, _ := mode.Evaluate(text)
+			stop, _ := newDecider(mode, cfg).ShouldStop(text, score, nil)
+			if stop != tt.want {
+				t.Errorf("ShouldStop(score %d, target %d) = %v, want %v", score, tt.target, stop, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopExprReplacesTargetScore(t *testing.T) {
+	expr, err := parseStopExpr("BOSS >= 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode := rerollMode{Evaluate: func(text string) (int, bool) { return 0, false }}
+	cfg := &Config{TargetScore: 1, StopExpr: expr}
+
+	if stop, _ := newDecider(mode, cfg).ShouldStop("DEX +9%\n", 5, nil); stop {
+		t.Error("stopped on the target score, want --stop-expr to replace it")
+	}
+}
+
+func TestExprDeciderQualifies(t *testing.T) {
+	expr, err := parseStopExpr("STR >= 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode := rerollMode{
+		Evaluate: func(text string) (int, bool) { return 0, false },
+		Qualifies: func(text string) (bool, string) {
+			if !primeLineIsMainStat(text, STR) {
+				return false, "STR is not on the prime (first) line"
+			}
+			return true, ""
+		},
+	}
+	cfg := &Config{StopExpr: expr}
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"matched on the prime line", "STR +12%\nDEX +9%\n", true},
+		{"matched off the prime line", "DEX +9%\nSTR +12%\n", false},
+		{"not matched", "STR +6%\nDEX +9%\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if stop, reason := newDecider(mode, cfg).ShouldStop(tt.text, 0, nil); stop != tt.want {
+				t.Errorf("ShouldStop(%q) = %v (%s), want %v", tt.text, stop, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopExprStatName(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Ignore Defense: +5%", "IED"},
+		{"IGN DEF +5%", "IED"},
+		{"Ign Def: +3%", "IED"},
+		{"DEF +120", ""},
+		{"Boss Monster Damage +10%", "BOSS"},
+		{"Damage +5%", "DAMAGE"},
+		{"Magic ATT +30", "MATT"},
+		{"ATT +30", "ATT"},
+		{"All Stats +6%", "ALLSTAT"},
+		{"LUK +12", "LUK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := stopExprStatName(tt.line); got != tt.want {
+				t.Errorf("stopExprStatName(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatCaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    statCaps
+		wantErr bool
+	}{
+		{"one cap", "STR=48", statCaps{"STR": 48}, false},
+		{"several, lower case and spaces", " str = 48, allstat=6 ,", statCaps{"STR": 48, "ALLSTAT": 6}, false},
+		{"unknown stat", "FOO=3", nil, true},
+		{"score is not a stat", "SCORE=100", nil, true},
+		{"missing max", "STR", nil, true},
+		{"zero max", "STR=0", nil, true},
+		{"not a number", "STR=lots", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatCaps(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatCaps(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseStatCaps(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatCapsStop(t *testing.T) {
+	caps := statCaps{"STR": 9, "ALLSTAT": 6}
+	mode := rerollMode{
+		Evaluate: func(text string) (int, bool) { return 0, false },
+	}
+	cfg := &Config{StatCaps: caps}
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"below the cap", "STR +8%\nDEX +9%\n", false},
+		{"at the cap", "STR +9%\nDEX +3%\n", true},
+		{"above the cap", "DEX +3%\nSTR +12%\n", true},
+		{"two lines don't add up", "STR +6%\nSTR +6%\n", false},
+		{"another stat at its cap", "All Stats +6%\n", true},
+		{"flat all stats is a different stat", "All Stats +6\n", false},
+		{"uncapped stat", "DEX +12%\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if stop, reason := newDecider(mode, cfg).ShouldStop(tt.text, 0, nil); stop != tt.want {
+				t.Errorf("ShouldStop(%q) = %v (%s), want %v", tt.text, stop, reason, tt.want)
+			}
+		})
+	}
+}
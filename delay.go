@@ -0,0 +1,122 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+const (
+	defaultRerollDelay   = 2 * time.Second        // Fixed wait after a reroll when not adapting
+	defaultClickSettle   = 200 * time.Millisecond // Wait after the reroll click before confirming
+	calibrationSamples   = 5                      // Attempts measured before the delay settles
+	calibrationMargin    = 200 * time.Millisecond // Added on top of the observed update time
+	changePollInterval   = 100 * time.Millisecond // Time between captures while measuring
+	changeDiffThreshold  = 1.0                    // Percent of changed pixels that counts as an update
+	changePixelTolerance = 24                     // Per-channel difference ignored as noise
+	calibrationFrames    = 2                      // Matching captures that mark the end of the animation
+	changeWaitLimit      = 1 * time.Second        // Longest wait for a reroll to visibly change the stats
+)
+
+// adaptiveDelay tunes the post-reroll wait toward the observed UI update time
+type adaptiveDelay struct {
+	min, max time.Duration
+	current  time.Duration
+	samples  int
+}
+
+// newAdaptiveDelay starts at the maximum delay so early attempts stay safe
+func newAdaptiveDelay(min, max time.Duration) *adaptiveDelay {
+	return &adaptiveDelay{min: min, max: max, current: max}
+}
+
+// Calibrating reports whether more measurements are still wanted
+func (d *adaptiveDelay) Calibrating() bool {
+	return d.samples < calibrationSamples
+}
+
+// Observe folds a measured update time into the running average and returns the new delay
+func (d *adaptiveDelay) Observe(measured time.Duration) time.Duration {
+	if !d.Calibrating() {
+		return d.current
+	}
+
+	target := measured + calibrationMargin
+	d.samples++
+	if d.samples == 1 {
+		d.current = target
+	} else {
+		d.current += (target - d.current) / time.Duration(d.samples)
+	}
+
+	if d.current < d.min {
+		d.current = d.min
+	}
+	if d.current > d.max {
+		d.current = d.max
+	}
+	return d.current
+}
+
+// Delay returns the current wait after a reroll
+func (d *adaptiveDelay) Delay() time.Duration {
+	return d.current
+}
+
+// measureUpdateTime captures the stat region until it has changed from
+// before and settled again, returning how long that took (capped at limit).
+// Stopping at the first change would time the start of the reroll animation
+// rather than the final stats. capture must return frames cropped like before.
+func measureUpdateTime(before *image.RGBA, capture func() (*image.RGBA, error), clock Clock, limit time.Duration) time.Duration {
+	elapsed, _ := waitForStableFrame(before, calibrationFrames, limit, capture, clock)
+	return elapsed
+}
+
+// waitForStableFrame captures the stat region until it has changed from
+// before and then frames consecutive captures match, so the reroll animation
+// has both started and finished before OCR. Without the first change, a UI
+// slow to start animating would have the old stats counted as stable. The
+// wait for that change is capped at changeWaitLimit, after which matching
+// frames count even if nothing changed: a reroll can land on the same stats,
+// and it shouldn't cost the whole timeout. A nil before skips that step. It
+// returns how long the wait took and false if the stats were still changing
+// at timeout. capture is injectable so the loop can be driven by synthetic
+// frames.
+func waitForStableFrame(before *image.RGBA, frames int, timeout time.Duration, capture func() (*image.RGBA, error), clock Clock) (time.Duration, bool) {
+	start := clock.Now()
+	changed := before == nil
+	var previous *image.RGBA
+	matching := 1
+	for clock.Now().Sub(start) < timeout {
+		clock.Sleep(changePollInterval)
+
+		img, err := capture()
+		if err != nil {
+			previous = nil
+			matching = 1
+			continue
+		}
+
+		if !changed {
+			diff, err := screenshot.DiffPercent(before, img, changePixelTolerance)
+			changed = err == nil && diff >= changeDiffThreshold
+		}
+
+		if previous != nil {
+			diff, err := screenshot.DiffPercent(previous, img, changePixelTolerance)
+			if err == nil && diff < changeDiffThreshold {
+				matching++
+			} else {
+				matching = 1
+			}
+		}
+		previous = img
+
+		elapsed := clock.Now().Sub(start)
+		if matching >= frames && (changed || elapsed >= changeWaitLimit) {
+			return elapsed, true
+		}
+	}
+	return timeout, false
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// solidFrame returns a small capture filled with one gray level
+func solidFrame(level uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetRGBA(x, y, color.RGBA{level, level, level, 255})
+		}
+	}
+	return img
+}
+
+// scriptedCapture returns the given gray levels in turn, repeating the last
+func scriptedCapture(levels ...uint8) func() (*image.RGBA, error) {
+	next := 0
+	return func() (*image.RGBA, error) {
+		level := levels[min(next, len(levels)-1)]
+		next++
+		return solidFrame(level), nil
+	}
+}
+
+// alternatingCapture returns frames that never settle
+func alternatingCapture() func() (*image.RGBA, error) {
+	next := uint8(0)
+	return func() (*image.RGBA, error) {
+		next += 100
+		return solidFrame(next), nil
+	}
+}
+
+func TestWaitForStableFrame(t *testing.T) {
+	before := solidFrame(0)
+
+	tests := []struct {
+		name        string
+		before      *image.RGBA
+		capture     func() (*image.RGBA, error)
+		wantElapsed time.Duration
+		wantStable  bool
+	}{
+		// Changes on the 3rd capture, matches on the 4th
+		{"changes then settles", before, scriptedCapture(0, 0, 100, 100), 4 * changePollInterval, true},
+		// A reroll that lands on the same stats stops waiting at the change cap
+		{"never changes", before, scriptedCapture(0), changeWaitLimit, true},
+		{"no before frame", nil, scriptedCapture(0), 2 * changePollInterval, true},
+		{"keeps changing", before, alternatingCapture(), 3 * time.Second, false},
+		{"capture keeps failing", before, func() (*image.RGBA, error) { return nil, errors.New("capture failed") }, 3 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			elapsed, stable := waitForStableFrame(tt.before, 2, 3*time.Second, tt.capture, clock)
+			if elapsed != tt.wantElapsed || stable != tt.wantStable {
+				t.Errorf("waitForStableFrame() = %s, %v, want %s, %v", elapsed, stable, tt.wantElapsed, tt.wantStable)
+			}
+			if slept := clock.Slept(); slept > 3*time.Second {
+				t.Errorf("slept %s, past the timeout", slept)
+			}
+		})
+	}
+}
+
+func TestMeasureUpdateTimeUnchangedReroll(t *testing.T) {
+	const limit = 5 * time.Second
+	clock := newFakeClock()
+
+	measured := measureUpdateTime(solidFrame(0), scriptedCapture(0), clock, limit)
+	if measured >= limit {
+		t.Errorf("measured %s for a reroll that left the stats unchanged, want less than the %s limit", measured, limit)
+	}
+}
+
+func TestAdaptiveDelay(t *testing.T) {
+	const minDelay, maxDelay = 500 * time.Millisecond, 3 * time.Second
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	tests := []struct {
+		name     string
+		measured []time.Duration
+		want     time.Duration
+	}{
+		{"no samples keeps the maximum", nil, maxDelay},
+		{"first sample plus margin", []time.Duration{ms(800)}, ms(1000)},
+		{"moving average", []time.Duration{ms(800), ms(1800)}, ms(1500)},
+		{"average of three", []time.Duration{ms(800), ms(1100), ms(1700)}, ms(1400)},
+		{"clamped to the minimum", []time.Duration{0}, minDelay},
+		{"clamped to the maximum", []time.Duration{5 * time.Second}, maxDelay},
+		{"settled after calibration", []time.Duration{ms(800), ms(800), ms(800), ms(800), ms(800), ms(2800)}, ms(1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newAdaptiveDelay(minDelay, maxDelay)
+			for _, measured := range tt.measured {
+				d.Observe(measured)
+			}
+			if got := d.Delay(); got != tt.want {
+				t.Errorf("Delay() = %v, want %v", got, tt.want)
+			}
+			if calibrating := len(tt.measured) < calibrationSamples; d.Calibrating() != calibrating {
+				t.Errorf("Calibrating() = %v after %d samples, want %v", d.Calibrating(), len(tt.measured), calibrating)
+			}
+		})
+	}
+}
+
+func TestMeasureUpdateTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []uint8
+		want   time.Duration
+	}{
+		{"changes on the first capture", []uint8{200}, 200 * time.Millisecond},
+		{"changes on the fourth capture", []uint8{0, 0, 0, 200}, 500 * time.Millisecond},
+		{"animates before settling", []uint8{0, 100, 150, 200}, 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := measureUpdateTime(solidFrame(0), scriptedCapture(tt.levels...), newFakeClock(), 5*time.Second)
+			if got != tt.want {
+				t.Errorf("measureUpdateTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// delayKind is the distribution a Delay draws from
+type delayKind int
+
+const (
+	delayFixed   delayKind = iota // Always base
+	delayUniform                  // Anywhere in [base, base+spread]
+	delayNormal                   // Normal with mean base and standard deviation spread
+)
+
+// Delay is a wait drawn from a distribution instead of a constant, so the
+// timing of clicks and key presses isn't perfectly regular. The zero value
+// is a fixed delay of 0.
+type Delay struct {
+	kind   delayKind
+	base   time.Duration
+	spread time.Duration
+	rng    *rand.Rand
+}
+
+// fixedDelay returns a Delay that is always d
+func fixedDelay(d time.Duration) Delay {
+	return Delay{base: d}
+}
+
+// parseDelay parses a delay spec: "2s" (fixed), "1.5s-2.5s" (uniform range)
+// or "normal:2s,300ms" (mean and standard deviation). Random delays draw
+// from rng.
+func parseDelay(spec string, rng *rand.Rand) (Delay, error) {
+	spec = strings.TrimSpace(spec)
+	invalid := fmt.Errorf("invalid delay %q: expected 2s, 1.5s-2.5s or normal:2s,300ms", spec)
+
+	if rest, ok := strings.CutPrefix(spec, "normal:"); ok {
+		meanText, stddevText, ok := strings.Cut(rest, ",")
+		mean, err1 := time.ParseDuration(strings.TrimSpace(meanText))
+		stddev, err2 := time.ParseDuration(strings.TrimSpace(stddevText))
+		if !ok || err1 != nil || err2 != nil || mean < 0 || stddev < 0 {
+			return Delay{}, invalid
+		}
+		return Delay{kind: delayNormal, base: mean, spread: stddev, rng: rng}, nil
+	}
+
+	if lowText, highText, ok := strings.Cut(spec, "-"); ok {
+		low, err1 := time.ParseDuration(strings.TrimSpace(lowText))
+		high, err2 := time.ParseDuration(strings.TrimSpace(highText))
+		if err1 != nil || err2 != nil || low < 0 || high < low {
+			return Delay{}, invalid
+		}
+		return Delay{kind: delayUniform, base: low, spread: high - low, rng: rng}, nil
+	}
+
+	d, err := time.ParseDuration(spec)
+	if err != nil || d < 0 {
+		return Delay{}, invalid
+	}
+	return fixedDelay(d), nil
+}
+
+// Duration draws the next wait. Normal draws are kept within four standard
+// deviations of the mean and never go below 0.
+func (d Delay) Duration() time.Duration {
+	switch d.kind {
+	case delayUniform:
+		if d.spread == 0 {
+			return d.base
+		}
+		return d.base + time.Duration(d.rng.Int63n(int64(d.spread)+1))
+	case delayNormal:
+		offset := d.rng.NormFloat64()
+		if offset > 4 {
+			offset = 4
+		} else if offset < -4 {
+			offset = -4
+		}
+		drawn := d.base + time.Duration(offset*float64(d.spread))
+		if drawn < 0 {
+			return 0
+		}
+		return drawn
+	default:
+		return d.base
+	}
+}
+
+// Min returns the shortest wait the delay can produce
+func (d Delay) Min() time.Duration {
+	if d.kind == delayNormal {
+		if low := d.base - 4*d.spread; low > 0 {
+			return low
+		}
+		return 0
+	}
+	return d.base
+}
+
+// String formats the delay the way parseDelay reads it
+func (d Delay) String() string {
+	switch d.kind {
+	case delayUniform:
+		return fmt.Sprintf("%s-%s", d.base, d.base+d.spread)
+	case delayNormal:
+		return fmt.Sprintf("normal:%s,%s", d.base, d.spread)
+	default:
+		return d.base.String()
+	}
+}
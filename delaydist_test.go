@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		spec     string
+		wantText string
+		min, max time.Duration
+	}{
+		{"2s", "2s", 2 * time.Second, 2 * time.Second},
+		{" 500ms ", "500ms", 500 * time.Millisecond, 500 * time.Millisecond},
+		{"1.5s-2.5s", "1.5s-2.5s", 1500 * time.Millisecond, 2500 * time.Millisecond},
+		{"1s - 1s", "1s-1s", time.Second, time.Second},
+		{"normal:2s,300ms", "normal:2s,300ms", 800 * time.Millisecond, 3200 * time.Millisecond},
+		{"normal:100ms,1s", "normal:100ms,1s", 0, 4100 * time.Millisecond},
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			delay, err := parseDelay(tt.spec, rng)
+			if err != nil {
+				t.Fatalf("parseDelay(%q) error: %v", tt.spec, err)
+			}
+			if got := delay.String(); got != tt.wantText {
+				t.Errorf("String() = %q, want %q", got, tt.wantText)
+			}
+			if got := delay.Min(); got != tt.min {
+				t.Errorf("Min() = %s, want %s", got, tt.min)
+			}
+			for i := 0; i < 100; i++ {
+				if d := delay.Duration(); d < tt.min || d > tt.max {
+					t.Fatalf("Duration() = %s, want within %s-%s", d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDelayErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"2",
+		"-1s",
+		"2s-1s",
+		"1s-",
+		"normal:2s",
+		"normal:2s,-1s",
+		"normal:abc,1s",
+	}
+
+	for _, spec := range tests {
+		if _, err := parseDelay(spec, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("parseDelay(%q) succeeded, want an error", spec)
+		}
+	}
+}
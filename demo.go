@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+// frameSource replaces the live window as the reroll loop's input. Capture
+// returns the current stat box and Reroll moves on to the next one.
+type frameSource interface {
+	Capture() (*image.RGBA, error)
+	Reroll()
+}
+
+// demoFrames cycles through saved stat box images on a timer, so the whole
+// OCR, scoring and display pipeline can run without MapleStory
+type demoFrames struct {
+	dir      string
+	frames   []*image.RGBA
+	next     int
+	interval time.Duration
+	clock    Clock
+}
+
+// loadDemoFrames loads every PNG in dir, in name order
+func loadDemoFrames(dir string, interval time.Duration, clock Clock) (*demoFrames, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no PNG frames found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	frames := make([]*image.RGBA, 0, len(paths))
+	for _, path := range paths {
+		img, err := screenshot.LoadImage(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load demo frame %s: %v", path, err)
+		}
+		frames = append(frames, img)
+	}
+	return &demoFrames{dir: dir, frames: frames, interval: interval, clock: clock}, nil
+}
+
+// Capture implements frameSource
+func (d *demoFrames) Capture() (*image.RGBA, error) {
+	return d.frames[d.next%len(d.frames)], nil
+}
+
+// Reroll implements frameSource, showing the next frame after the interval
+func (d *demoFrames) Reroll() {
+	d.clock.Sleep(d.interval)
+	d.next++
+}
+
+// String describes the source for the start-up banner
+func (d *demoFrames) String() string {
+	return fmt.Sprintf("%d frame(s) from %s, one every %s", len(d.frames), d.dir, d.interval)
+}
+
+// enableDemo switches cfg to read frames from dir, turning off the options
+// that need a live game window
+func enableDemo(cfg *Config, dir string, interval time.Duration) error {
+	frames, err := loadDemoFrames(dir, interval, cfg.clock())
+	if err != nil {
+		return err
+	}
+	cfg.Frames = frames
+
+	cfg.UICheck = nil
+	cfg.AutoRestore = false
+	cfg.ParkCursor = false
+	cfg.AdaptiveDelay = false
+	cfg.ItemNameRegion = screenshot.Region{}
+	cfg.VerifyFrames = 0
+	cfg.RegionScan = false
+	cfg.WatchdogTimeout = 0
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+// attemptResult records the outcome of a single reroll attempt. It is shared
+// by the history, the live stream and anything else that reports attempts.
+type attemptResult struct {
+	Time    time.Time `json:"time"`
+	Mode    string    `json:"mode"`
+	Item    string    `json:"item,omitempty"`
+	Attempt int       `json:"attempt"`
+	Score   int       `json:"score"`
+	Success bool      `json:"success"`
+	Text    string    `json:"text"`
+	Metrics Metrics   `json:"metrics"`
+}
+
+// attemptHistory is a fixed-size ring buffer of the most recent attempts
+type attemptHistory struct {
+	entries  []attemptResult
+	next     int
+	count    int
+	recorded int
+}
+
+// newAttemptHistory creates a history that keeps the last size attempts
+func newAttemptHistory(size int) *attemptHistory {
+	if size < 1 {
+		size = 1
+	}
+	return &attemptHistory{entries: make([]attemptResult, size)}
+}
+
+// Add stores an attempt, overwriting the oldest one when the buffer is full
+func (h *attemptHistory) Add(entry attemptResult) {
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.count < len(h.entries) {
+		h.count++
+	}
+	h.recorded++
+}
+
+// Len returns the number of attempts currently stored
+func (h *attemptHistory) Len() int {
+	return h.count
+}
+
+// Recorded returns the number of attempts added so far, including the ones
+// the buffer no longer holds
+func (h *attemptHistory) Recorded() int {
+	return h.recorded
+}
+
+// Recent returns up to n of the latest attempts, oldest first
+func (h *attemptHistory) Recent(n int) []attemptResult {
+	if n > h.count {
+		n = h.count
+	}
+	recent := make([]attemptResult, 0, n)
+	start := h.next - n
+	for i := 0; i < n; i++ {
+		idx := (start + i + len(h.entries)) % len(h.entries)
+		recent = append(recent, h.entries[idx])
+	}
+	return recent
+}
+
+// IsStuck reports whether the last n attempts all produced the same non-empty text
+func (h *attemptHistory) IsStuck(n int) bool {
+	if n < 2 || h.count < n {
+		return false
+	}
+	recent := h.Recent(n)
+	if recent[0].Text == "" {
+		return false
+	}
+	for _, entry := range recent[1:] {
+		if entry.Text != recent[0].Text {
+			return false
+		}
+	}
+	return true
+}
+
+// MeanScore returns the average score of the last n attempts, and false
+// if fewer than n attempts have been recorded
+func (h *attemptHistory) MeanScore(n int) (float64, bool) {
+	if n < 1 || h.count < n {
+		return 0, false
+	}
+	total := 0
+	for _, entry := range h.Recent(n) {
+		total += entry.Score
+	}
+	return float64(total) / float64(n), true
+}
+
+// trendGuard flags a likely misconfiguration when rerolled scores stay well
+// below the score the item started with
+type trendGuard struct {
+	window   int     // Number of rerolled attempts averaged (0 = off)
+	ratio    float64 // Abort when the mean falls below ratio * baseline
+	baseline int
+	hasBase  bool
+}
+
+// Check records the first score as the baseline and reports whether the
+// mean of the last window rerolled attempts has fallen below the threshold
+func (g *trendGuard) Check(h *attemptHistory, score int) (float64, bool) {
+	if g.window <= 0 {
+		return 0, false
+	}
+	if !g.hasBase {
+		g.baseline = score
+		g.hasBase = true
+		return 0, false
+	}
+	// The baseline attempt itself is never part of the averaged window
+	if g.baseline <= 0 || h.Len() <= g.window {
+		return 0, false
+	}
+	mean, ok := h.MeanScore(g.window)
+	if !ok {
+		return 0, false
+	}
+	return mean, mean < g.ratio*float64(g.baseline)
+}
+
+// plateauDetector tracks the best score seen so far and reports when
+// too many attempts have passed without a new best
+type plateauDetector struct {
+	limit     int
+	epsilon   int // Scores within epsilon of the best count as unchanged
+	best      int
+	sinceBest int
+	seen      bool
+}
+
+// Observe records a score and returns true once the plateau limit is reached.
+// A limit of 0 disables the detector. Only a score more than epsilon above
+// the best resets the count, so OCR jitter of a point or two doesn't.
+func (p *plateauDetector) Observe(score int) bool {
+	if p.limit <= 0 {
+		return false
+	}
+	if !p.seen || score > p.best+p.epsilon {
+		p.best = score
+		p.sinceBest = 0
+		p.seen = true
+		return false
+	}
+	p.sinceBest++
+	return p.sinceBest >= p.limit
+}
+
+// flameBudget counts the flames actually consumed: rerolls after which the
+// stats changed. Clicks that didn't change anything don't count.
+type flameBudget struct {
+	limit int // Flames that may be consumed (0 = unlimited)
+	used  int
+}
+
+// Observe compares the stat box captured before and after a reroll and
+// counts a consumed flame if it changed. It compares the frames, not the OCR
+// text, so a misread of unchanged stats doesn't count as a flame. Frames that
+// can't be compared (the window was resized) count as changed. It returns
+// true if a flame was counted.
+func (b *flameBudget) Observe(before, after *image.RGBA) bool {
+	diff, err := screenshot.DiffPercent(before, after, changePixelTolerance)
+	if err == nil && diff < changeDiffThreshold {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// Exhausted reports whether the budget has been used up
+func (b *flameBudget) Exhausted() bool {
+	return b.limit > 0 && b.used >= b.limit
+}
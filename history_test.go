@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFlameBudgetObserve(t *testing.T) {
+	noisy := solidFrame(100)
+	noisy.Pix[0] += 10 // within changePixelTolerance
+
+	tests := []struct {
+		name   string
+		before *image.RGBA
+		after  *image.RGBA
+		want   bool
+	}{
+		{"same frame", solidFrame(100), solidFrame(100), false},
+		{"capture noise", solidFrame(100), noisy, false},
+		{"stats changed", solidFrame(100), solidFrame(200), true},
+		{"size changed", solidFrame(100), image.NewRGBA(image.Rect(0, 0, 4, 4)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := &flameBudget{}
+			if got := budget.Observe(tt.before, tt.after); got != tt.want {
+				t.Errorf("Observe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrendGuard(t *testing.T) {
+	tests := []struct {
+		name   string
+		window int
+		scores []int // the first one is the baseline
+		want   bool
+	}{
+		{"off", 0, []int{4, 0, 0, 0}, false},
+		{"window not filled", 3, []int{4, 0, 0}, false},
+		{"average below ratio", 3, []int{4, 1, 1, 1}, true},
+		{"average at ratio", 3, []int{4, 2, 2, 2}, false},
+		{"only the window is averaged", 3, []int{4, 0, 3, 3, 3}, false},
+		{"largest window", historySize - 1, append([]int{4}, make([]int, historySize-1)...), true},
+		{"zero baseline", 3, []int{0, 0, 0, 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := &trendGuard{window: tt.window, ratio: 0.5}
+			history := newAttemptHistory(historySize)
+			var low bool
+			for i, score := range tt.scores {
+				history.Add(attemptResult{Attempt: i, Score: score})
+				if _, low = guard.Check(history, score); low && i < len(tt.scores)-1 {
+					t.Fatalf("Check() reported a low trend early, at attempt %d", i)
+				}
+			}
+			if low != tt.want {
+				t.Errorf("Check() = %v, want %v", low, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlateauDetector(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		scores []int
+		want   int // index of the score that reports the plateau (-1 = never)
+	}{
+		{"off", 0, []int{3, 1, 1, 1, 1}, -1},
+		{"improving", 3, []int{1, 2, 3, 4, 5, 6}, -1},
+		{"plateaued", 3, []int{3, 1, 2, 3}, 3},
+		{"new best resets the count", 3, []int{3, 1, 2, 4, 1, 2, 4}, 6},
+		{"varying rolls below the best", 2, []int{5, 4, 1}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &plateauDetector{limit: tt.limit}
+			got := -1
+			for i, score := range tt.scores {
+				if p.Observe(score) {
+					got = i
+					break
+				}
+			}
+			if got != tt.want {
+				t.Errorf("plateau reported at score %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlateauDetectorEpsilon(t *testing.T) {
+	tests := []struct {
+		name    string
+		epsilon int
+		scores  []int
+		want    bool // plateau reported on the last score
+	}{
+		{"no epsilon, gain of one is a new best", 0, []int{10, 11, 11}, false},
+		{"gain below epsilon", 2, []int{10, 11, 11}, true},
+		{"gain at epsilon", 2, []int{10, 12, 12}, true},
+		{"gain just past epsilon", 2, []int{10, 13, 13}, false},
+		{"drops never reset", 2, []int{10, 4, 9}, true},
+		// The best only moves on a real gain, so small steps can't creep past it
+		{"creeping gains", 2, []int{10, 12, 14}, false},
+		{"creeping gains below epsilon", 2, []int{10, 11, 12}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &plateauDetector{limit: 2, epsilon: tt.epsilon}
+			var got bool
+			for _, score := range tt.scores {
+				got = p.Observe(score)
+			}
+			if got != tt.want {
+				t.Errorf("Observe(%v) = %v, want %v", tt.scores, got, tt.want)
+			}
+		})
+	}
+}
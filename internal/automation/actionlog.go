@@ -0,0 +1,83 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Action is a single thing the tool did to the game, or decided to do
+type Action struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"` // click, key, capture, decision
+	X       int       `json:"x,omitempty"`
+	Y       int       `json:"y,omitempty"`
+	Key     int       `json:"key,omitempty"`
+	Attempt int       `json:"attempt,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// ActionLogger writes actions as JSON lines in the order they happen
+type ActionLogger struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// actionLogger receives every action once set; nil disables logging
+var actionLogger *ActionLogger
+
+// NewActionLogger creates temp/actions_<timestamp>.jsonl inside dir
+func NewActionLogger(dir string) (*ActionLogger, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create action log directory: %v", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("actions_%s.jsonl", time.Now().Format("20060102_150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create action log: %v", err)
+	}
+
+	return &ActionLogger{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Path returns the file the actions are written to
+func (l *ActionLogger) Path() string {
+	return l.file.Name()
+}
+
+// Log appends an action, stamping it with the current time if unset
+func (l *ActionLogger) Log(action Action) {
+	if action.Time.IsZero() {
+		action.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(action); err != nil {
+		fmt.Printf("Warning: Failed to write action log: %v\n", err)
+	}
+}
+
+// Close flushes and closes the log file
+func (l *ActionLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// SetActionLogger routes all recorded actions to the given logger (nil disables)
+func SetActionLogger(l *ActionLogger) {
+	actionLogger = l
+}
+
+// LogAction records an action if an action logger is set
+func LogAction(action Action) {
+	if actionLogger != nil {
+		actionLogger.Log(action)
+	}
+}
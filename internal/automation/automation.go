@@ -0,0 +1,147 @@
+// Package automation provides functions for sending keyboard and mouse input to MapleStory
+package automation
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"maple_flame/internal/window"
+)
+
+// Windows API for sending keypress and mouse clicks
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	procSetCursorPos     = user32.NewProc("SetCursorPos")
+	procGetAsyncKeyState = user32.NewProc("GetAsyncKeyState")
+)
+
+const (
+	VK_SPACE   = 0x20
+	VK_RETURN  = 0x0D
+	VK_SHIFT   = 0x10
+	VK_CONTROL = 0x11
+	VK_MENU    = 0x12 // Alt
+	VK_PAUSE   = 0x13
+	VK_ESCAPE  = 0x1B
+	VK_END     = 0x23
+	VK_HOME    = 0x24
+	VK_INSERT  = 0x2D
+	VK_DELETE  = 0x2E
+	VK_F1      = 0x70
+
+	// Mouse event constants
+	MOUSEEVENTF_LEFTDOWN = 0x0002
+	MOUSEEVENTF_LEFTUP   = 0x0004
+)
+
+// sleep is the wait between the steps of a key press or click
+var sleep = time.Sleep
+
+// SetSleep replaces the function used to wait between input steps, so they
+// follow the caller's clock (nil = time.Sleep)
+func SetSleep(f func(time.Duration)) {
+	if f == nil {
+		f = time.Sleep
+	}
+	sleep = f
+}
+
+// DefaultKeyHold is how long PressKey holds a key down
+const DefaultKeyHold = 50 * time.Millisecond
+
+// PressKey simulates a key press using the working method from git history
+func PressKey(keyCode int) {
+	PressKeyHold(keyCode, DefaultKeyHold)
+}
+
+// PressKeyHold simulates a key press, holding the key down for hold. Some
+// clients drop presses that are released too quickly.
+func PressKeyHold(keyCode int, hold time.Duration) {
+	// Key down
+	if err := sendKey(keyCode, 0); err != nil {
+		fmt.Printf("⚠️ Key press failed: %v ", err)
+	}
+	sleep(hold)
+
+	// Key up, even if the key down was blocked, so no key is left held
+	sendKey(keyCode, KEYEVENTF_KEYUP)
+
+	LogAction(Action{Type: "key", Key: keyCode})
+}
+
+// ReleaseModifiers sends key-up events for Ctrl, Shift and Alt so no modifier
+// is left logically held after the tool stops mid-sequence
+func ReleaseModifiers() {
+	for _, keyCode := range []int{VK_CONTROL, VK_SHIFT, VK_MENU} {
+		sendKey(keyCode, KEYEVENTF_KEYUP)
+	}
+}
+
+// cursorScale converts the logical coordinates computed from GetWindowRect
+// into the physical pixels SetCursorPos expects on a scaled display (1 = off)
+var cursorScale = 1.0
+
+// SetCursorScale sets the logical-to-physical scale applied to every cursor
+// move, e.g. 1.5 on a 150% display whose window coordinates are logical
+func SetCursorScale(scale float64) {
+	if scale <= 0 {
+		scale = 1
+	}
+	cursorScale = scale
+}
+
+// CursorScale returns the scale set with SetCursorScale (1 = off)
+func CursorScale() float64 {
+	return cursorScale
+}
+
+// ToPhysical scales a logical screen position by the cursor scale
+func ToPhysical(x, y int, scale float64) (int, int) {
+	return int(float64(x)*scale + 0.5), int(float64(y)*scale + 0.5)
+}
+
+// setCursorPos moves the cursor to a logical screen position
+func setCursorPos(x, y int) error {
+	physX, physY := ToPhysical(x, y, cursorScale)
+	ret, _, _ := procSetCursorPos.Call(uintptr(physX), uintptr(physY))
+	if ret == 0 {
+		return fmt.Errorf("failed to set cursor position")
+	}
+	return nil
+}
+
+// Click moves the cursor to the absolute screen position and left-clicks
+func Click(x, y int) error {
+	// Move cursor to click position
+	if err := setCursorPos(x, y); err != nil {
+		return err
+	}
+
+	sleep(100 * time.Millisecond)
+
+	// Perform mouse click (left button down and up)
+	if err := sendMouse(MOUSEEVENTF_LEFTDOWN); err != nil {
+		return err
+	}
+	sleep(50 * time.Millisecond)
+
+	if err := sendMouse(MOUSEEVENTF_LEFTUP); err != nil {
+		return err
+	}
+
+	LogAction(Action{Type: "click", X: x, Y: y})
+
+	return nil
+}
+
+// ParkCursor moves the cursor to a harmless spot inside the window (offset
+// from its top-left corner) so it doesn't cover the stat text in captures
+func ParkCursor(rect *window.WindowRect, safeX, safeY int) error {
+	x, y, err := window.AbsoluteClickPos(rect, safeX, safeY)
+	if err != nil {
+		return fmt.Errorf("invalid cursor park position: %v", err)
+	}
+
+	return setCursorPos(x, y)
+}
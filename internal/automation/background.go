@@ -0,0 +1,85 @@
+package automation
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	procPostMessage    = user32.NewProc("PostMessageW")
+	procMapVirtualKeyW = user32.NewProc("MapVirtualKeyW")
+)
+
+// Window messages posted for background input
+const (
+	WM_KEYDOWN     = 0x0100
+	WM_KEYUP       = 0x0101
+	WM_MOUSEMOVE   = 0x0200
+	WM_LBUTTONDOWN = 0x0201
+	WM_LBUTTONUP   = 0x0202
+
+	MK_LBUTTON      = 0x0001 // wParam flag: left button is down
+	MAPVK_VK_TO_VSC = 0      // MapVirtualKeyW: virtual key to scan code
+)
+
+// pointLParam packs client coordinates into a mouse message LPARAM: x in the
+// low word, y in the high word
+func pointLParam(x, y int) uintptr {
+	return uintptr(uint16(int16(x))) | uintptr(uint16(int16(y)))<<16
+}
+
+// keyLParam builds a key message LPARAM: a repeat count of 1 and the scan
+// code, plus the previous-state and transition bits for key up
+func keyLParam(scanCode uintptr, up bool) uintptr {
+	lParam := 1 | (scanCode&0xFF)<<16
+	if up {
+		lParam |= 1<<30 | 1<<31
+	}
+	return lParam
+}
+
+// postMessage posts a message to a window's queue without waiting for it
+func postMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) error {
+	ret, _, err := procPostMessage.Call(hwnd, uintptr(msg), wParam, lParam)
+	if ret == 0 {
+		return fmt.Errorf("PostMessage failed: %v", err)
+	}
+	return nil
+}
+
+// ClickRerollBackground posts a left click at a position in the window's
+// client area. Unlike Click it doesn't activate the window or move the
+// cursor, so the user can keep working in another window. Some clients
+// ignore posted input; for those use the foreground Click.
+func ClickRerollBackground(hwnd uintptr, x, y int) error {
+	lParam := pointLParam(x, y)
+	if err := postMessage(hwnd, WM_MOUSEMOVE, 0, lParam); err != nil {
+		return err
+	}
+	if err := postMessage(hwnd, WM_LBUTTONDOWN, MK_LBUTTON, lParam); err != nil {
+		return err
+	}
+	sleep(50 * time.Millisecond)
+	if err := postMessage(hwnd, WM_LBUTTONUP, 0, lParam); err != nil {
+		return err
+	}
+
+	LogAction(Action{Type: "click", X: x, Y: y, Detail: "background"})
+	return nil
+}
+
+// PressKeyBackground posts a key press to the window, holding it down for
+// hold, without activating it
+func PressKeyBackground(hwnd uintptr, keyCode int, hold time.Duration) error {
+	scanCode, _, _ := procMapVirtualKeyW.Call(uintptr(keyCode), MAPVK_VK_TO_VSC)
+	if err := postMessage(hwnd, WM_KEYDOWN, uintptr(keyCode), keyLParam(scanCode, false)); err != nil {
+		return err
+	}
+	sleep(hold)
+	if err := postMessage(hwnd, WM_KEYUP, uintptr(keyCode), keyLParam(scanCode, true)); err != nil {
+		return err
+	}
+
+	LogAction(Action{Type: "key", Key: keyCode, Detail: "background"})
+	return nil
+}
@@ -0,0 +1,67 @@
+package automation
+
+import "strings"
+
+// Hotkey reports each press of a key combination once: holding the keys
+// down does not repeat, and a combo already held when the hotkey is created
+// has to be released first
+type Hotkey struct {
+	combo StopKeyConfig
+	down  bool
+	state func(key int) uintptr
+}
+
+// NewHotkey parses a single "modifier+key" or "key" combo, e.g. "ctrl+f2"
+func NewHotkey(spec string) (*Hotkey, error) {
+	combo, err := parseStopKey(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Hotkey{combo: combo, down: true, state: keyState}, nil
+}
+
+// Pressed reports whether the combo went down since the last call
+func (h *Hotkey) Pressed() bool {
+	down := isComboPressed(h.combo, h.state)
+	pressed := down && !h.down
+	h.down = down
+	return pressed
+}
+
+// Name returns the combo for display, e.g. "Ctrl+F2"
+func (h *Hotkey) Name() string {
+	return h.combo.Name
+}
+
+// HoldKey reports whether a key or combo is held down right now, for pausing
+// while the user takes over by hand. A bare modifier such as "shift" is
+// accepted on its own.
+type HoldKey struct {
+	combo StopKeyConfig
+	state func(key int) uintptr
+}
+
+// NewHoldKey parses a "shift", "ctrl", "alt", "modifier+key" or "key" spec
+func NewHoldKey(spec string) (*HoldKey, error) {
+	name := strings.ToUpper(strings.TrimSpace(spec))
+	if modifier, ok := modifierKeys[name]; ok {
+		combo := StopKeyConfig{Key: modifier, Name: name[:1] + strings.ToLower(name[1:])}
+		return &HoldKey{combo: combo, state: keyState}, nil
+	}
+
+	combo, err := parseStopKey(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &HoldKey{combo: combo, state: keyState}, nil
+}
+
+// Held reports whether the key is down at this moment
+func (h *HoldKey) Held() bool {
+	return isComboPressed(h.combo, h.state)
+}
+
+// Name returns the key for display, e.g. "Shift"
+func (h *HoldKey) Name() string {
+	return h.combo.Name
+}
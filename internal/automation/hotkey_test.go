@@ -0,0 +1,67 @@
+package automation
+
+import "testing"
+
+// heldKeys returns a key state function reporting the given keys as down
+func heldKeys(keys ...int) func(key int) uintptr {
+	return func(key int) uintptr {
+		for _, k := range keys {
+			if k == key {
+				return 0x8000
+			}
+		}
+		return 0
+	}
+}
+
+func TestHoldKeyTransitions(t *testing.T) {
+	tests := []struct {
+		spec  string
+		polls [][]int // Keys held down at each poll
+		want  []bool  // Held() at each poll
+	}{
+		{"shift", [][]int{{}, {VK_SHIFT}, {VK_SHIFT}, {}, {VK_SHIFT}}, []bool{false, true, true, false, true}},
+		{"ctrl", [][]int{{VK_SHIFT}, {VK_CONTROL}, {VK_CONTROL, VK_SHIFT}, {}}, []bool{false, true, true, false}},
+		{"ctrl+f2", [][]int{{VK_CONTROL}, {VK_CONTROL, VK_F1 + 1}, {VK_F1 + 1}, {}}, []bool{false, true, false, false}},
+		{"f3", [][]int{{}, {VK_F1 + 2}, {VK_F1 + 2}, {}}, []bool{false, true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			key, err := NewHoldKey(tt.spec)
+			if err != nil {
+				t.Fatalf("NewHoldKey(%q) error: %v", tt.spec, err)
+			}
+			// Unlike Hotkey, holding the key keeps reporting it on every poll
+			for i, held := range tt.polls {
+				key.state = heldKeys(held...)
+				if got := key.Held(); got != tt.want[i] {
+					t.Errorf("poll %d: Held() = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHoldKeyNames(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"shift", "Shift"},
+		{" ALT ", "Alt"},
+		{"ctrl+f2", "Ctrl+F2"},
+	}
+	for _, tt := range tests {
+		key, err := NewHoldKey(tt.spec)
+		if err != nil {
+			t.Fatalf("NewHoldKey(%q) error: %v", tt.spec, err)
+		}
+		if got := key.Name(); got != tt.want {
+			t.Errorf("NewHoldKey(%q).Name() = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+	if _, err := NewHoldKey("win"); err == nil {
+		t.Error("NewHoldKey(\"win\") succeeded, want an error")
+	}
+}
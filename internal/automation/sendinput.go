@@ -0,0 +1,86 @@
+package automation
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+var procSendInput = user32.NewProc("SendInput")
+
+// INPUT types and event flags for SendInput
+const (
+	INPUT_MOUSE    = 0
+	INPUT_KEYBOARD = 1
+
+	KEYEVENTF_KEYUP = 0x0002
+)
+
+// MOUSEINPUT mirrors the Win32 struct of the same name
+type MOUSEINPUT struct {
+	Dx        int32
+	Dy        int32
+	MouseData uint32
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+// KEYBDINPUT mirrors the Win32 struct of the same name
+type KEYBDINPUT struct {
+	VirtualKeyCode uint16
+	ScanCode       uint16
+	Flags          uint32
+	Time           uint32
+	ExtraInfo      uintptr
+}
+
+// mouseInput is a Win32 INPUT holding a MOUSEINPUT. MOUSEINPUT is the
+// largest member of INPUT's union, so this is the size SendInput expects.
+type mouseInput struct {
+	Type uint32
+	Mi   MOUSEINPUT
+}
+
+// keyboardInput is a Win32 INPUT holding a KEYBDINPUT, padded to the size
+// of the union's largest member. SendInput rejects any other size.
+type keyboardInput struct {
+	Type uint32
+	Ki   KEYBDINPUT
+	_    [8]byte
+}
+
+// Both INPUT variants must be exactly sizeof(INPUT): 40 bytes on 64-bit,
+// 28 on 32-bit. A mismatch indexes past the array and fails to compile.
+var (
+	_ = [1]struct{}{}[unsafe.Sizeof(mouseInput{})-unsafe.Sizeof(keyboardInput{})]
+	_ = [1]struct{}{}[unsafe.Sizeof(keyboardInput{})-(16+3*unsafe.Sizeof(uintptr(0)))]
+)
+
+// sendKey sends a single key down or key up event
+func sendKey(keyCode int, flags uint32) error {
+	in := keyboardInput{
+		Type: INPUT_KEYBOARD,
+		Ki:   KEYBDINPUT{VirtualKeyCode: uint16(keyCode), Flags: flags},
+	}
+	return sendInput(unsafe.Pointer(&in), unsafe.Sizeof(in))
+}
+
+// sendMouse sends a single mouse event at the current cursor position
+func sendMouse(flags uint32) error {
+	in := mouseInput{
+		Type: INPUT_MOUSE,
+		Mi:   MOUSEINPUT{Flags: flags},
+	}
+	return sendInput(unsafe.Pointer(&in), unsafe.Sizeof(in))
+}
+
+// sendInput passes one INPUT to SendInput. SendInput returns how many
+// events it inserted; 0 means the input was blocked, e.g. by UIPI when the
+// game runs elevated and this tool doesn't.
+func sendInput(in unsafe.Pointer, size uintptr) error {
+	sent, _, err := procSendInput.Call(1, uintptr(in), size)
+	if sent == 0 {
+		return fmt.Errorf("SendInput was blocked: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package automation
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestInputLayout(t *testing.T) {
+	// Win32 INPUT is a DWORD type followed by a union aligned to the pointer
+	// size, so the union starts at 8 on 64-bit and at 4 on 32-bit
+	ptr := unsafe.Sizeof(uintptr(0))
+	wantSize := 16 + 3*ptr // 40 on 64-bit, 28 on 32-bit
+
+	tests := []struct {
+		name string
+		got  uintptr
+		want uintptr
+	}{
+		{"sizeof keyboard INPUT", unsafe.Sizeof(keyboardInput{}), wantSize},
+		{"sizeof mouse INPUT", unsafe.Sizeof(mouseInput{}), wantSize},
+		{"keyboard union offset", unsafe.Offsetof(keyboardInput{}.Ki), ptr},
+		{"mouse union offset", unsafe.Offsetof(mouseInput{}.Mi), ptr},
+		{"KEYBDINPUT.wVk", unsafe.Offsetof(KEYBDINPUT{}.VirtualKeyCode), 0},
+		{"KEYBDINPUT.wScan", unsafe.Offsetof(KEYBDINPUT{}.ScanCode), 2},
+		{"KEYBDINPUT.dwFlags", unsafe.Offsetof(KEYBDINPUT{}.Flags), 4},
+		{"KEYBDINPUT.time", unsafe.Offsetof(KEYBDINPUT{}.Time), 8},
+		{"KEYBDINPUT.dwExtraInfo", unsafe.Offsetof(KEYBDINPUT{}.ExtraInfo), ptr + 8},
+		{"MOUSEINPUT.dwFlags", unsafe.Offsetof(MOUSEINPUT{}.Flags), 12},
+		{"MOUSEINPUT.time", unsafe.Offsetof(MOUSEINPUT{}.Time), 16},
+		{"MOUSEINPUT.dwExtraInfo", unsafe.Offsetof(MOUSEINPUT{}.ExtraInfo), 16 + ptr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package automation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StopKeyConfig is a key combination that stops the reroll loop
+type StopKeyConfig struct {
+	Modifier int    // Virtual key that must be held (0 = none)
+	Key      int    // Virtual key that must be pressed
+	Name     string // Display name, e.g. "Ctrl+F1"
+}
+
+// DefaultStopKey is the combination used when none is configured
+var DefaultStopKey = StopKeyConfig{Modifier: VK_CONTROL, Key: VK_F1, Name: "Ctrl+F1"}
+
+// stopKeys are the combinations checked by CheckStopKey; any of them stops the loop
+var stopKeys = []StopKeyConfig{DefaultStopKey}
+
+// stopKeyArmed records, per stop key, whether it has been seen released since
+// the keys were set. A combo held down at launch (e.g. left over from another
+// app) must be let go before it can stop the loop.
+var stopKeyArmed = make([]bool, len(stopKeys))
+
+// modifierKeys maps modifier names to virtual key codes
+var modifierKeys = map[string]int{
+	"CTRL":    VK_CONTROL,
+	"CONTROL": VK_CONTROL,
+	"SHIFT":   VK_SHIFT,
+	"ALT":     VK_MENU,
+}
+
+// namedKeys maps non-alphanumeric key names to virtual key codes
+var namedKeys = map[string]int{
+	"ESC":    VK_ESCAPE,
+	"ESCAPE": VK_ESCAPE,
+	"PAUSE":  VK_PAUSE,
+	"END":    VK_END,
+	"HOME":   VK_HOME,
+	"INSERT": VK_INSERT,
+	"DELETE": VK_DELETE,
+}
+
+// ParseStopKeys parses a comma-separated list of combos such as "ctrl+f1,esc,ctrl+q"
+func ParseStopKeys(spec string) ([]StopKeyConfig, error) {
+	var combos []StopKeyConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		combo, err := parseStopKey(part)
+		if err != nil {
+			return nil, err
+		}
+		combos = append(combos, combo)
+	}
+
+	if len(combos) == 0 {
+		return nil, fmt.Errorf("no stop key given")
+	}
+	return combos, nil
+}
+
+// parseStopKey parses a single "modifier+key" or "key" combo
+func parseStopKey(combo string) (StopKeyConfig, error) {
+	parts := strings.Split(strings.ToUpper(combo), "+")
+	if len(parts) > 2 {
+		return StopKeyConfig{}, fmt.Errorf("invalid stop key %q: only one modifier is supported", combo)
+	}
+
+	config := StopKeyConfig{}
+	if len(parts) == 2 {
+		modifier, ok := modifierKeys[strings.TrimSpace(parts[0])]
+		if !ok {
+			return StopKeyConfig{}, fmt.Errorf("invalid stop key %q: unknown modifier %q (use ctrl, shift or alt)", combo, parts[0])
+		}
+		config.Modifier = modifier
+	}
+
+	keyName := strings.TrimSpace(parts[len(parts)-1])
+	key, err := keyCode(keyName)
+	if err != nil {
+		return StopKeyConfig{}, fmt.Errorf("invalid stop key %q: %v", combo, err)
+	}
+	config.Key = key
+
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		names[i] = part[:1] + strings.ToLower(part[1:])
+	}
+	config.Name = strings.Join(names, "+")
+
+	return config, nil
+}
+
+// keyCode converts a key name (A-Z, 0-9, F1-F12 or a named key) to a virtual key code
+func keyCode(name string) (int, error) {
+	if code, ok := namedKeys[name]; ok {
+		return code, nil
+	}
+	if len(name) == 1 && (name[0] >= 'A' && name[0] <= 'Z' || name[0] >= '0' && name[0] <= '9') {
+		// Letters and digits use their ASCII codes as virtual key codes
+		return int(name[0]), nil
+	}
+	var fn int
+	if _, err := fmt.Sscanf(name, "F%d", &fn); err == nil && fn >= 1 && fn <= 12 && name == fmt.Sprintf("F%d", fn) {
+		return VK_F1 + fn - 1, nil
+	}
+	return 0, fmt.Errorf("unknown key %q", name)
+}
+
+// SetStopKeys replaces the combinations that stop the loop
+func SetStopKeys(combos []StopKeyConfig) {
+	if len(combos) == 0 {
+		combos = []StopKeyConfig{DefaultStopKey}
+	}
+	stopKeys = combos
+	stopKeyArmed = make([]bool, len(combos))
+}
+
+// StopKeyNames returns the configured combinations for display, e.g. "Ctrl+F1 or Esc"
+func StopKeyNames() string {
+	names := make([]string, len(stopKeys))
+	for i, combo := range stopKeys {
+		names[i] = combo.Name
+	}
+	return strings.Join(names, " or ")
+}
+
+// CheckStopKey checks if any of the configured stop key combinations has
+// been freshly pressed
+func CheckStopKey() bool {
+	return checkStopKeys(stopKeys, stopKeyArmed, keyState)
+}
+
+// checkStopKeys reports whether any armed combo is pressed, arming each combo
+// the first time it is seen released
+func checkStopKeys(combos []StopKeyConfig, armed []bool, state func(key int) uintptr) bool {
+	for i, combo := range combos {
+		pressed := isComboPressed(combo, state)
+		if !armed[i] {
+			armed[i] = !pressed
+			continue
+		}
+		if pressed {
+			return true
+		}
+	}
+	return false
+}
+
+// isComboPressed checks a combo against the given key-state function
+func isComboPressed(combo StopKeyConfig, state func(key int) uintptr) bool {
+	if combo.Modifier != 0 && state(combo.Modifier)&0x8000 == 0 {
+		return false
+	}
+	return state(combo.Key)&0x8000 != 0
+}
+
+// keyState returns the GetAsyncKeyState result for a virtual key
+func keyState(key int) uintptr {
+	state, _, _ := procGetAsyncKeyState.Call(uintptr(key))
+	return state
+}
@@ -0,0 +1,109 @@
+package automation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStopKeys(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []StopKeyConfig
+	}{
+		{"ctrl+f1", []StopKeyConfig{{Modifier: VK_CONTROL, Key: VK_F1, Name: "Ctrl+F1"}}},
+		{"ctrl+f1,esc", []StopKeyConfig{
+			{Modifier: VK_CONTROL, Key: VK_F1, Name: "Ctrl+F1"},
+			{Key: VK_ESCAPE, Name: "Esc"},
+		}},
+		{" Shift+Q , alt+f12 ,, 9", []StopKeyConfig{
+			{Modifier: VK_SHIFT, Key: 'Q', Name: "Shift+Q"},
+			{Modifier: VK_MENU, Key: VK_F1 + 11, Name: "Alt+F12"},
+			{Key: '9', Name: "9"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseStopKeys(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseStopKeys(%q) error: %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStopKeys(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStopKeysErrors(t *testing.T) {
+	for _, spec := range []string{"", " , ", "ctrl+shift+q", "win+q", "f13", "ctrl+f1,nope"} {
+		if _, err := ParseStopKeys(spec); err == nil {
+			t.Errorf("ParseStopKeys(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCheckStopKeys(t *testing.T) {
+	ctrlF1 := StopKeyConfig{Modifier: VK_CONTROL, Key: VK_F1, Name: "Ctrl+F1"}
+	esc := StopKeyConfig{Key: VK_ESCAPE, Name: "Esc"}
+
+	tests := []struct {
+		name   string
+		combos []StopKeyConfig
+		polls  [][]int // Keys held down at each poll
+		want   []bool  // Result of each poll
+	}{
+		{"not pressed", []StopKeyConfig{ctrlF1}, [][]int{{}, {}}, []bool{false, false}},
+		{"pressed after release", []StopKeyConfig{ctrlF1}, [][]int{{}, {VK_CONTROL, VK_F1}}, []bool{false, true}},
+		{"held at launch", []StopKeyConfig{ctrlF1}, [][]int{{VK_CONTROL, VK_F1}, {VK_CONTROL, VK_F1}, {}, {VK_CONTROL, VK_F1}}, []bool{false, false, false, true}},
+		{"modifier missing", []StopKeyConfig{ctrlF1}, [][]int{{}, {VK_F1}}, []bool{false, false}},
+		{"no modifier", []StopKeyConfig{esc}, [][]int{{}, {VK_ESCAPE}}, []bool{false, true}},
+		{"any combo", []StopKeyConfig{ctrlF1, esc}, [][]int{{}, {VK_ESCAPE}}, []bool{false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			armed := make([]bool, len(tt.combos))
+			for i, held := range tt.polls {
+				state := func(key int) uintptr {
+					for _, k := range held {
+						if k == key {
+							return 0x8000
+						}
+					}
+					return 0
+				}
+				if got := checkStopKeys(tt.combos, armed, state); got != tt.want[i] {
+					t.Errorf("poll %d: checkStopKeys() = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsComboPressed(t *testing.T) {
+	tests := []struct {
+		name  string
+		combo StopKeyConfig
+		state map[int]uintptr // GetAsyncKeyState results; missing keys are 0
+		want  bool
+	}{
+		{"both down", StopKeyConfig{Modifier: VK_CONTROL, Key: VK_F1}, map[int]uintptr{VK_CONTROL: 0x8000, VK_F1: 0x8000}, true},
+		{"key only", StopKeyConfig{Modifier: VK_CONTROL, Key: VK_F1}, map[int]uintptr{VK_F1: 0x8000}, false},
+		{"modifier only", StopKeyConfig{Modifier: VK_CONTROL, Key: VK_F1}, map[int]uintptr{VK_CONTROL: 0x8000}, false},
+		{"pressed-since bit only", StopKeyConfig{Key: VK_ESCAPE}, map[int]uintptr{VK_ESCAPE: 0x0001}, false},
+		{"down with pressed-since bit", StopKeyConfig{Key: VK_ESCAPE}, map[int]uintptr{VK_ESCAPE: 0x8001}, true},
+		{"other key down", StopKeyConfig{Key: VK_ESCAPE}, map[int]uintptr{VK_F1: 0x8000}, false},
+		{"letter with shift", StopKeyConfig{Modifier: VK_SHIFT, Key: 'Q'}, map[int]uintptr{VK_SHIFT: 0x8000, 'Q': 0x8000}, true},
+		{"letter with wrong modifier", StopKeyConfig{Modifier: VK_SHIFT, Key: 'Q'}, map[int]uintptr{VK_MENU: 0x8000, 'Q': 0x8000}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := func(key int) uintptr { return tt.state[key] }
+			if got := isComboPressed(tt.combo, state); got != tt.want {
+				t.Errorf("isComboPressed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package ocr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ExtractTextWithConfidence runs tesseract with TSV output and returns the
+// text along with the mean word confidence (0-100). A read with no words has
+// a confidence of 0.
+func ExtractTextWithConfidence(imagePath string) (string, float64, error) {
+	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
+		return "", 0, fmt.Errorf("image file does not exist: %s", imagePath)
+	}
+
+	outputPath := strings.TrimSuffix(imagePath, ".png")
+	cmd := exec.Command("tesseract", tesseractArgs(imagePath, outputPath, "tsv")...)
+	if err := cmd.Run(); err != nil {
+		return "", 0, fmt.Errorf("tesseract failed: %v", err)
+	}
+
+	tsvBytes, err := os.ReadFile(outputPath + ".tsv")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read OCR output: %v", err)
+	}
+	os.Remove(outputPath + ".tsv")
+
+	text, confidence := parseTSV(string(tsvBytes))
+	return text, confidence, nil
+}
+
+// parseTSV rebuilds the text from tesseract's TSV output, one line per OCR
+// line, and averages the confidence of the recognized words
+func parseTSV(tsv string) (string, float64) {
+	var lines []string
+	var current []string
+	lastLine := ""
+	total, words := 0.0, 0
+
+	for i, row := range strings.Split(tsv, "\n") {
+		fields := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		// Skip the header and anything that isn't a word row (level 5)
+		if i == 0 || len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		word := strings.TrimSpace(fields[11])
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if word == "" || err != nil || conf < 0 {
+			continue
+		}
+
+		lineKey := strings.Join(fields[1:5], ".") // page.block.paragraph.line
+		if lineKey != lastLine && len(current) > 0 {
+			lines = append(lines, strings.Join(current, " "))
+			current = nil
+		}
+		lastLine = lineKey
+		current = append(current, word)
+
+		total += conf
+		words++
+	}
+	if len(current) > 0 {
+		lines = append(lines, strings.Join(current, " "))
+	}
+
+	if words == 0 {
+		return "", 0
+	}
+	return strings.Join(lines, "\n") + "\n", total / float64(words)
+}
@@ -0,0 +1,157 @@
+package ocr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StatAlias replaces text that OCR produces with the stat name it should read as
+type StatAlias struct {
+	From string
+	To   string
+}
+
+// StatAliases are applied in file order, so overlapping aliases such as
+// "Al Stats" and "Al" always give the same result
+type StatAliases []StatAlias
+
+// String lists the aliases as from=to pairs, or "off" when there are none
+func (a StatAliases) String() string {
+	if len(a) == 0 {
+		return "off"
+	}
+	pairs := make([]string, len(a))
+	for i, alias := range a {
+		pairs[i] = alias.From + "=" + alias.To
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// gluedStatPattern finds a stat name stuck directly onto the number or percent
+// sign of the previous stat, e.g. "Max HP+300STR+9%" or "DEX: +9%LUK: +9%"
+var gluedStatPattern = regexp.MustCompile(`(?i)([0-9%])\s*(STR|DEX|INT|LUK|MAX HP|MAX MP|ALL STATS?|ATTACK POWER|MAGIC ATTACK|BOSS|IGNORE|DEFENSE|SPEED|JUMP)\b`)
+
+// LoadStatAliases reads "from=to" alias lines from an OCR fixes file, in
+// file order. Blank lines and lines starting with # are ignored. A from given
+// twice keeps its first position and its last replacement.
+func LoadStatAliases(path string) (StatAliases, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OCR fixes file: %v", err)
+	}
+	defer f.Close()
+
+	var aliases StatAliases
+	position := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		from, to, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(from) == "" {
+			return nil, fmt.Errorf("invalid alias on line %d: %q (expected from=to)", lineNumber, line)
+		}
+		alias := StatAlias{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+		if i, ok := position[alias.From]; ok {
+			aliases[i] = alias
+			continue
+		}
+		position[alias.From] = len(aliases)
+		aliases = append(aliases, alias)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read OCR fixes file: %v", err)
+	}
+
+	return aliases, nil
+}
+
+// NormalizeStatText applies the aliases and splits stat names that OCR glued
+// onto the previous line, so each stat ends up on its own line
+func NormalizeStatText(text string, aliases StatAliases) string {
+	for _, alias := range aliases {
+		text = strings.ReplaceAll(text, alias.From, alias.To)
+	}
+
+	return gluedStatPattern.ReplaceAllString(text, "$1\n$2")
+}
+
+// statValuePattern matches the bonus value on a stat line, e.g. "+30" or "+6 %"
+var statValuePattern = regexp.MustCompile(`\+\s*([0-9]+)\s*(%?)`)
+
+// ExtractStatValue returns the bonus value on a single stat line and whether
+// it is a percentage. ok is false when the line has no "+N" value.
+func ExtractStatValue(line string) (value int, percent bool, ok bool) {
+	matches := statValuePattern.FindStringSubmatch(line)
+	if len(matches) < 3 {
+		return 0, false, false
+	}
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false, false
+	}
+	return value, matches[2] == "%", true
+}
+
+// MergeVertical joins the text of two vertically adjacent, overlapping
+// captures. Lines read in both (the overlap at the seam) are kept once, and
+// a top line cut off at the seam gives way to the full line read below it.
+// maxOverlap is how many lines fit in the shared rows; matching lines past
+// that are separate stat lines that happen to read the same, and are kept.
+func MergeVertical(top, bottom string, maxOverlap int) string {
+	topLines := nonEmptyLines(top)
+	bottomLines := nonEmptyLines(bottom)
+
+	// Longest run of lines that ends the top text and starts the bottom one
+	overlap := 0
+	for k := min(len(topLines), len(bottomLines), maxOverlap); k > 0; k-- {
+		if equalLines(topLines[len(topLines)-k:], bottomLines[:k]) {
+			overlap = k
+			break
+		}
+	}
+
+	if overlap == 0 && len(topLines) > 0 && len(bottomLines) > 0 &&
+		strings.HasPrefix(bottomLines[0], topLines[len(topLines)-1]) {
+		topLines = topLines[:len(topLines)-1]
+	}
+
+	merged := append(topLines, bottomLines[overlap:]...)
+	if len(merged) == 0 {
+		return ""
+	}
+	return strings.Join(merged, "\n") + "\n"
+}
+
+// nonEmptyLines splits text into trimmed lines, dropping blank ones
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// equalLines reports whether two line slices match exactly
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeVertical(t *testing.T) {
+	tests := []struct {
+		name        string
+		top, bottom string
+		maxOverlap  int
+		want        string
+	}{
+		{"no overlap", "STR +9%\nDEX +6%\n", "LUK +3%\n", 1, "STR +9%\nDEX +6%\nLUK +3%\n"},
+		{"seam line read twice", "STR +9%\nDEX +6%\n", "DEX +6%\nLUK +3%\n", 1, "STR +9%\nDEX +6%\nLUK +3%\n"},
+		{"cut-off line replaced", "STR +9%\nDEX +", "DEX +6%\nLUK +3%\n", 1, "STR +9%\nDEX +6%\nLUK +3%\n"},
+		{"blank lines dropped", "\nSTR +9%\n\n", "  \nLUK +3%\n", 1, "STR +9%\nLUK +3%\n"},
+		{"empty bottom", "STR +9%\n", "", 1, "STR +9%\n"},
+		{"both empty", "", "", 1, ""},
+		{
+			"repeated lines past the overlap kept",
+			"STR +9%\nSTR +9%\n", "STR +9%\nSTR +9%\nLUK +3%\n", 1,
+			"STR +9%\nSTR +9%\nSTR +9%\nLUK +3%\n",
+		},
+		{
+			"wider overlap",
+			"STR +9%\nDEX +6%\nINT +3%\n", "DEX +6%\nINT +3%\nLUK +3%\n", 2,
+			"STR +9%\nDEX +6%\nINT +3%\nLUK +3%\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeVertical(tt.top, tt.bottom, tt.maxOverlap); got != tt.want {
+				t.Errorf("MergeVertical() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeStatTextGlued(t *testing.T) {
+	type stat struct {
+		value   int
+		percent bool
+	}
+	tests := []struct {
+		text string
+		want []stat
+	}{
+		{"Max HP+300STR+9%", []stat{{300, false}, {9, true}}},
+		{"DEX: +9%LUK: +9%", []stat{{9, true}, {9, true}}},
+		{"INT +12 %All Stats +6%", []stat{{12, true}, {6, true}}},
+		{"STR +30\nDEX +30", []stat{{30, false}, {30, false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []stat
+			for _, line := range nonEmptyLines(NormalizeStatText(tt.text, nil)) {
+				value, percent, ok := ExtractStatValue(line)
+				if !ok {
+					t.Fatalf("no value on line %q", line)
+				}
+				got = append(got, stat{value, percent})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stats = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeStatTextAliasOrder(t *testing.T) {
+	aliases := StatAliases{
+		{From: "lnt", To: "INT"},
+		{From: "ln", To: "In"},
+	}
+
+	// Run it repeatedly: applied out of order, "ln" would turn "lnt" into "Int"
+	for i := 0; i < 20; i++ {
+		if got := NormalizeStatText("lnt +3%", aliases); got != "INT +3%" {
+			t.Fatalf("NormalizeStatText() = %q, want %q", got, "INT +3%")
+		}
+	}
+}
+
+func TestLoadStatAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixes.txt")
+	content := "# OCR fixes\nlnt=INT\n\nln = In\nlnt=INT \n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadStatAliases(path)
+	if err != nil {
+		t.Fatalf("LoadStatAliases() error: %v", err)
+	}
+	want := StatAliases{{From: "lnt", To: "INT"}, {From: "ln", To: "In"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadStatAliases() = %v, want %v", got, want)
+	}
+}
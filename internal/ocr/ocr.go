@@ -11,8 +11,52 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+// keepEnhanced controls whether ExtractFlameText leaves its enhanced image on disk
+var keepEnhanced = false
+
+// SetKeepEnhanced makes ExtractFlameText keep the enhanced image it feeds to
+// tesseract (saved next to the original as <name>_enhanced.png)
+func SetKeepEnhanced(keep bool) {
+	keepEnhanced = keep
+}
+
+// tessdataDir and tessConfig are passed to every tesseract run when set
+var (
+	tessdataDir string
+	tessConfig  string
 )
 
+// SetTesseractOptions points tesseract at a custom tessdata directory and/or
+// a config file name (looked up in tessdata/configs). Empty values are left out.
+func SetTesseractOptions(dataDir, configName string) {
+	tessdataDir = dataDir
+	tessConfig = configName
+}
+
+// TesseractOptions returns the tessdata directory and config name set with
+// SetTesseractOptions ("" = tesseract's default)
+func TesseractOptions() (dataDir, configName string) {
+	return tessdataDir, tessConfig
+}
+
+// tesseractArgs builds the tesseract command line for one image: the image
+// and output base, the tessdata directory, any extra options, then the config
+func tesseractArgs(imagePath, outputPath string, options ...string) []string {
+	args := []string{imagePath, outputPath}
+	if tessdataDir != "" {
+		args = append(args, "--tessdata-dir", tessdataDir)
+	}
+	args = append(args, options...)
+	if tessConfig != "" {
+		args = append(args, tessConfig)
+	}
+	return args
+}
+
 // ExtractText extracts text from an image file using tesseract
 func ExtractText(imagePath string) (string, error) {
 	// Verify the image file exists
@@ -23,7 +67,7 @@ func ExtractText(imagePath string) (string, error) {
 	// Call tesseract via command line
 	// Using the image path directly without creating a temp copy
 	outputPath := strings.TrimSuffix(imagePath, ".png")
-	cmd := exec.Command("tesseract", imagePath, outputPath)
+	cmd := exec.Command("tesseract", tesseractArgs(imagePath, outputPath)...)
 	err := cmd.Run()
 	if err != nil {
 		// If tesseract fails, return a simulated result for testing
@@ -57,17 +101,21 @@ func ExtractText(imagePath string) (string, error) {
 	return text, nil
 }
 
+// percentPattern matches "+20%", tolerating the space OCR often inserts
+// before the percent sign ("+20 %")
+const percentPattern = "\\+([0-9]+)\\s*%"
+
 // ExtractItemDropRate extracts Item Drop Rate percentage from text
 // It finds all occurrences and sums them up
 func ExtractItemDropRate(text string) int {
 	// Search for "Drop Rate" instead of "item drop" for more reliable detection
-	return extractPercentage(text, "drop rate", "\\+([0-9]+)%")
+	return extractPercentage(text, "drop rate", percentPattern)
 }
 
 // ExtractMesosObtained extracts Mesos Obtained percentage from text
 // It finds all occurrences and sums them up
 func ExtractMesosObtained(text string) int {
-	return extractPercentage(text, "mesos obtained", "\\+([0-9]+)%")
+	return extractPercentage(text, "mesos obtained", percentPattern)
 }
 
 // Helper function to extract and sum percentages
@@ -121,6 +169,28 @@ func DetectKeywords(text string) (bool, bool, int) {
 	return hasItemKeyword, hasMesosKeyword, primeLineCount
 }
 
+// DetectPrimeLines is DetectKeywords with an option to only count a keyword
+// as a prime line when a positive percentage was read next to it. This stops
+// partial reads like "Item Drop Rate: +%" from counting as prime lines.
+func DetectPrimeLines(text string, requireValue bool) (bool, bool, int) {
+	hasItemKeyword, hasMesosKeyword, _ := DetectKeywords(text)
+
+	if requireValue {
+		hasItemKeyword = hasItemKeyword && ExtractItemDropRate(text) > 0
+		hasMesosKeyword = hasMesosKeyword && ExtractMesosObtained(text) > 0
+	}
+
+	primeLineCount := 0
+	if hasItemKeyword {
+		primeLineCount++
+	}
+	if hasMesosKeyword {
+		primeLineCount++
+	}
+
+	return hasItemKeyword, hasMesosKeyword, primeLineCount
+}
+
 // ExtractFlameText extracts text from flame stat images using optimized tesseract settings
 func ExtractFlameText(imagePath string) (string, error) {
 	// Verify the image file exists
@@ -135,6 +205,11 @@ func ExtractFlameText(imagePath string) (string, error) {
 		return extractTextDirectly(imagePath)
 	}
 
+	// Remove the enhanced image afterwards unless asked to keep it
+	if !keepEnhanced {
+		defer os.Remove(enhancedPath)
+	}
+
 	// Call tesseract with optimized settings for enhanced image
 	outputPath := strings.TrimSuffix(enhancedPath, ".png")
 	
@@ -142,10 +217,10 @@ func ExtractFlameText(imagePath string) (string, error) {
 	// --oem 3: Use default OCR Engine Mode (neural networks LSTM + legacy)
 	// --psm 6: Assume a single uniform block of text
 	// --dpi 300: Tell tesseract the enhanced image is higher DPI
-	cmd := exec.Command("tesseract", enhancedPath, outputPath, 
-		"--oem", "3", 
+	cmd := exec.Command("tesseract", tesseractArgs(enhancedPath, outputPath,
+		"--oem", "3",
 		"--psm", "6",
-		"--dpi", "300")
+		"--dpi", "300")...)
 	
 	err = cmd.Run()
 	if err != nil {
@@ -171,7 +246,7 @@ func ExtractFlameText(imagePath string) (string, error) {
 // extractTextDirectly runs OCR on the original image without enhancement
 func extractTextDirectly(imagePath string) (string, error) {
 	outputPath := strings.TrimSuffix(imagePath, ".png")
-	cmd := exec.Command("tesseract", imagePath, outputPath, "--oem", "3", "--psm", "6")
+	cmd := exec.Command("tesseract", tesseractArgs(imagePath, outputPath, "--oem", "3", "--psm", "6")...)
 	
 	err := cmd.Run()
 	if err != nil {
@@ -230,7 +305,8 @@ func enhanceImageForOCR(imagePath string) (string, error) {
 	}
 	defer fOut.Close()
 
-	err = png.Encode(fOut, enhanced)
+	// Tesseract only needs luminance, and grayscale PNGs are much smaller
+	err = png.Encode(fOut, screenshot.ToGrayscaleFast(enhanced))
 	if err != nil {
 		return "", fmt.Errorf("failed to encode enhanced image: %v", err)
 	}
@@ -238,21 +314,24 @@ func enhanceImageForOCR(imagePath string) (string, error) {
 	return enhancedPath, nil
 }
 
-// simpleUpscale2x performs a simple 2x nearest neighbor upscale
+// simpleUpscale2x performs a simple 2x nearest neighbor upscale, or less if
+// 2x would exceed the enhanced image size cap
 func simpleUpscale2x(img *image.RGBA) *image.RGBA {
 	bounds := img.Bounds()
 	originalWidth := bounds.Dx()
 	originalHeight := bounds.Dy()
 	
-	newWidth := originalWidth * 2
-	newHeight := originalHeight * 2
+	// Stay within the enhanced image size cap
+	scaleFactor := screenshot.CapScaleFactor(originalWidth, originalHeight, 2)
+	newWidth := originalWidth * scaleFactor
+	newHeight := originalHeight * scaleFactor
 	
 	enlarged := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
 	
 	for y := 0; y < newHeight; y++ {
 		for x := 0; x < newWidth; x++ {
-			origX := x / 2
-			origY := y / 2
+			origX := x / scaleFactor
+			origY := y / scaleFactor
 			
 			if origX >= originalWidth {
 				origX = originalWidth - 1
@@ -0,0 +1,182 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// LoadImage reads a PNG file and converts it to RGBA
+func LoadImage(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %v", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PNG: %v", err)
+	}
+
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			rgba.Set(x-bounds.Min.X, y-bounds.Min.Y, img.At(x, y))
+		}
+	}
+
+	return rgba, nil
+}
+
+// DiffPercent returns the percentage of pixels whose channels differ by more
+// than tolerance between two images of the same size
+func DiffPercent(a, b *image.RGBA, tolerance uint8) (float64, error) {
+	boundsA := a.Bounds()
+	boundsB := b.Bounds()
+	if boundsA.Dx() != boundsB.Dx() || boundsA.Dy() != boundsB.Dy() {
+		return 0, fmt.Errorf("image sizes differ: %dx%d vs %dx%d",
+			boundsA.Dx(), boundsA.Dy(), boundsB.Dx(), boundsB.Dy())
+	}
+
+	total := boundsA.Dx() * boundsA.Dy()
+	if total == 0 {
+		return 0, nil
+	}
+
+	changed := 0
+	for y := 0; y < boundsA.Dy(); y++ {
+		for x := 0; x < boundsA.Dx(); x++ {
+			pa := a.RGBAAt(boundsA.Min.X+x, boundsA.Min.Y+y)
+			pb := b.RGBAAt(boundsB.Min.X+x, boundsB.Min.Y+y)
+			if channelDiff(pa.R, pb.R) > tolerance ||
+				channelDiff(pa.G, pb.G) > tolerance ||
+				channelDiff(pa.B, pb.B) > tolerance {
+				changed++
+			}
+		}
+	}
+
+	return float64(changed) * 100 / float64(total), nil
+}
+
+// TextBounds returns the smallest rectangle containing all bright "ink" pixels
+// (flame text is light on a dark panel). It is empty if no pixel is brighter
+// than the threshold.
+func TextBounds(img *image.RGBA, threshold uint8) image.Rectangle {
+	bounds := img.Bounds()
+	text := image.Rectangle{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if luminance(img.RGBAAt(x, y)) > threshold {
+				text = text.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return text
+}
+
+// RowInkDensity returns, for each row of the image, the fraction of pixels
+// brighter than the threshold
+func RowInkDensity(img *image.RGBA, threshold uint8) []float64 {
+	bounds := img.Bounds()
+	density := make([]float64, bounds.Dy())
+	if bounds.Dx() == 0 {
+		return density
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		ink := 0
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if luminance(img.RGBAAt(x, y)) > threshold {
+				ink++
+			}
+		}
+		density[y-bounds.Min.Y] = float64(ink) / float64(bounds.Dx())
+	}
+	return density
+}
+
+// CropToTextBand crops an image vertically to the band between the first and
+// last rows whose ink density reaches minDensity, plus margin rows on each
+// side. The image is returned unchanged when no row has enough ink.
+func CropToTextBand(img *image.RGBA, threshold uint8, minDensity float64, margin int) *image.RGBA {
+	density := RowInkDensity(img, threshold)
+	first, last := -1, -1
+	for row, d := range density {
+		if d >= minDensity {
+			if first < 0 {
+				first = row
+			}
+			last = row
+		}
+	}
+	if first < 0 {
+		return img
+	}
+
+	top := first - margin
+	if top < 0 {
+		top = 0
+	}
+	bottom := len(density) - 1 - last - margin
+	if bottom < 0 {
+		bottom = 0
+	}
+	return Crop(img, Margins{Top: top, Bottom: bottom})
+}
+
+// channelDiff returns the absolute difference between two color channels
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// luminance converts a pixel to its grayscale brightness
+func luminance(pixel color.RGBA) uint8 {
+	return uint8((uint32(pixel.R)*299 + uint32(pixel.G)*587 + uint32(pixel.B)*114) / 1000)
+}
+
+// IsBlankRegion reports whether every pixel is darker than the threshold,
+// which is what a capture of a hidden or minimized window looks like
+func IsBlankRegion(img *image.RGBA, threshold uint8) bool {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if luminance(img.RGBAAt(x, y)) > threshold {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsUniformColor reports whether every pixel is within tolerance of the first
+// one, e.g. a black, white or single-color transition frame
+func IsUniformColor(img *image.RGBA, tolerance uint8) bool {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return true
+	}
+
+	first := img.RGBAAt(bounds.Min.X, bounds.Min.Y)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := img.RGBAAt(x, y)
+			if channelDiff(pixel.R, first.R) > tolerance ||
+				channelDiff(pixel.G, first.G) > tolerance ||
+				channelDiff(pixel.B, first.B) > tolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
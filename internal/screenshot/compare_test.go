@@ -0,0 +1,118 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFixture saves a 20x10 dark panel with a bright 4x2 block of "text"
+// at (x, y) as a PNG and returns its path
+func writeFixture(t *testing.T, name string, x, y int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for py := 0; py < 10; py++ {
+		for px := 0; px < 20; px++ {
+			c := color.RGBA{20, 20, 30, 255}
+			if px >= x && px < x+4 && py >= y && py < y+2 {
+				c = color.RGBA{240, 240, 240, 255}
+			}
+			img.SetRGBA(px, py, c)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiffPercentFixtures(t *testing.T) {
+	load := func(path string) *image.RGBA {
+		t.Helper()
+		img, err := LoadImage(path)
+		if err != nil {
+			t.Fatalf("LoadImage(%s) error = %v", path, err)
+		}
+		return img
+	}
+	reference := load(writeFixture(t, "reference.png", 2, 2))
+
+	tests := []struct {
+		name     string
+		capture  *image.RGBA
+		want     float64
+		wantText image.Rectangle
+	}{
+		{"identical", load(writeFixture(t, "same.png", 2, 2)), 0, image.Rect(2, 2, 6, 4)},
+		// The block overlaps itself in two of its four columns, so 8 of the 200 pixels differ
+		{"shifted right", load(writeFixture(t, "shifted.png", 4, 2)), 4, image.Rect(4, 2, 8, 4)},
+		{"moved away", load(writeFixture(t, "moved.png", 12, 6)), 8, image.Rect(12, 6, 16, 8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffPercent(tt.capture, reference, 24)
+			if err != nil {
+				t.Fatalf("DiffPercent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DiffPercent() = %.1f%%, want %.1f%%", got, tt.want)
+			}
+			if text := TextBounds(tt.capture, 128); text != tt.wantText {
+				t.Errorf("TextBounds() = %v, want %v", text, tt.wantText)
+			}
+		})
+	}
+
+	if _, err := DiffPercent(image.NewRGBA(image.Rect(0, 0, 10, 10)), reference, 24); err == nil {
+		t.Error("DiffPercent() of different sizes returned no error")
+	}
+}
+
+func TestIsUniformColor(t *testing.T) {
+	fill := func(c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 6, 3))
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 6; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+	withPixel := func(img *image.RGBA, c color.RGBA) *image.RGBA {
+		img.SetRGBA(5, 2, c)
+		return img
+	}
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want bool
+	}{
+		{"black", fill(color.RGBA{0, 0, 0, 255}), true},
+		{"white", fill(color.RGBA{255, 255, 255, 255}), true},
+		{"UI blue", fill(color.RGBA{30, 60, 140, 255}), true},
+		{"noise within tolerance", withPixel(fill(color.RGBA{30, 60, 140, 255}), color.RGBA{38, 52, 140, 255}), true},
+		{"one pixel past tolerance", withPixel(fill(color.RGBA{30, 60, 140, 255}), color.RGBA{39, 60, 140, 255}), false},
+		{"text on a panel", withPixel(fill(color.RGBA{0, 0, 0, 255}), color.RGBA{255, 255, 255, 255}), false},
+		{"empty", image.NewRGBA(image.Rect(0, 0, 0, 0)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUniformColor(tt.img, 8); got != tt.want {
+				t.Errorf("IsUniformColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
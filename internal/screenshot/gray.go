@@ -0,0 +1,50 @@
+package screenshot
+
+import (
+	"image"
+)
+
+// ToGrayscaleFast converts an RGBA image to grayscale using the same
+// 299/587/114 luminance weights as the rest of the package, reading the
+// Pix slice directly instead of going through the color interfaces
+func ToGrayscaleFast(img *image.RGBA) *image.Gray {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		src := img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		dst := gray.Pix[y*gray.Stride : y*gray.Stride+width]
+		for x := range dst {
+			r := uint32(src[x*4])
+			g := uint32(src[x*4+1])
+			b := uint32(src[x*4+2])
+			dst[x] = uint8((r*299 + g*587 + b*114) / 1000)
+		}
+	}
+
+	return gray
+}
+
+// InvertImage returns a copy of img with its colors inverted, turning light
+// text on a dark panel into dark text on a light one. Alpha is kept.
+func InvertImage(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	inverted := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		src := img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		dst := inverted.Pix[y*inverted.Stride : y*inverted.Stride+width*4]
+		for x := 0; x < width*4; x += 4 {
+			dst[x] = 255 - src[x]
+			dst[x+1] = 255 - src[x+1]
+			dst[x+2] = 255 - src[x+2]
+			dst[x+3] = src[x+3]
+		}
+	}
+
+	return inverted
+}
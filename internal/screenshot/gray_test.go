@@ -0,0 +1,102 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// noiseImage returns a width x height image filled with varied colors
+func noiseImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * 37), uint8(y * 91), uint8((x + y) * 13), 255})
+		}
+	}
+	return img
+}
+
+func TestToGrayscaleFast(t *testing.T) {
+	full := noiseImage(17, 9)
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"full image", full},
+		// A sub-image shares the parent's Pix and stride
+		{"sub-image", full.SubImage(image.Rect(3, 2, 12, 8)).(*image.RGBA)},
+		{"white and black", func() *image.RGBA {
+			img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+			img.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+			img.SetRGBA(1, 0, color.RGBA{0, 0, 0, 255})
+			return img
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gray := ToGrayscaleFast(tt.img)
+			bounds := tt.img.Bounds()
+			if gray.Bounds() != image.Rect(0, 0, bounds.Dx(), bounds.Dy()) {
+				t.Fatalf("bounds = %v, want %dx%d from the origin", gray.Bounds(), bounds.Dx(), bounds.Dy())
+			}
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					want := luminance(tt.img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y))
+					if got := gray.GrayAt(x, y).Y; got != want {
+						t.Fatalf("pixel (%d,%d) = %d, want %d", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkToGrayscaleFast(b *testing.B) {
+	img := noiseImage(400, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ToGrayscaleFast(img)
+	}
+}
+
+func BenchmarkLuminanceLoop(b *testing.B) {
+	img := noiseImage(400, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gray := image.NewGray(img.Bounds())
+		for y := 0; y < 200; y++ {
+			for x := 0; x < 400; x++ {
+				gray.SetGray(x, y, color.Gray{Y: luminance(img.RGBAAt(x, y))})
+			}
+		}
+	}
+}
+
+func TestInvertImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(2, 0, color.RGBA{10, 128, 200, 77})
+
+	inverted := InvertImage(img)
+	want := []color.RGBA{{255, 255, 255, 255}, {0, 0, 0, 255}, {245, 127, 55, 77}}
+	for x, w := range want {
+		if got := inverted.RGBAAt(x, 0); got != w {
+			t.Errorf("pixel %d = %v, want %v", x, got, w)
+		}
+	}
+
+	// Inverting twice gives back the original, sub-images included
+	sub := noiseImage(9, 5).SubImage(image.Rect(2, 1, 7, 4)).(*image.RGBA)
+	twice := InvertImage(InvertImage(sub))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			if got, w := twice.RGBAAt(x, y), sub.RGBAAt(2+x, 1+y); got != w {
+				t.Fatalf("double inversion pixel (%d,%d) = %v, want %v", x, y, got, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// MontageFrame is one capture in a session montage
+type MontageFrame struct {
+	Attempt int
+	Image   *image.RGBA
+}
+
+const (
+	montageGap        = 4 // Pixels between stacked frames
+	montageLabelScale = 2 // Size of each font pixel
+	montageLabelPad   = 3 // Space around the attempt label
+)
+
+var (
+	montageBackground = color.RGBA{R: 24, G: 24, B: 24, A: 255}
+	montageLabelColor = color.RGBA{R: 255, G: 220, B: 80, A: 255}
+)
+
+// montageGlyphs is a 3x5 pixel font for attempt labels; each row is three
+// bits, most significant on the left
+var montageGlyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'#': {5, 7, 5, 7, 5},
+}
+
+// StackVertical stacks images top to bottom in order, gap pixels apart, on
+// a dark background as wide as the widest image
+func StackVertical(images []*image.RGBA, gap int) *image.RGBA {
+	width, height := 0, 0
+	for i, img := range images {
+		bounds := img.Bounds()
+		if bounds.Dx() > width {
+			width = bounds.Dx()
+		}
+		height += bounds.Dy()
+		if i > 0 {
+			height += gap
+		}
+	}
+
+	stacked := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(stacked, stacked.Bounds(), &image.Uniform{C: montageBackground}, image.Point{}, draw.Src)
+
+	y := 0
+	for _, img := range images {
+		bounds := img.Bounds()
+		draw.Draw(stacked, image.Rect(0, y, bounds.Dx(), y+bounds.Dy()), img, bounds.Min, draw.Src)
+		y += bounds.Dy() + gap
+	}
+	return stacked
+}
+
+// labelFrame returns the frame with a strip above it reading "#<attempt>"
+func labelFrame(frame MontageFrame) *image.RGBA {
+	label := "#" + strconv.Itoa(frame.Attempt)
+	glyphWidth := 4 * montageLabelScale // 3 pixels plus a 1 pixel space
+	stripHeight := 5*montageLabelScale + 2*montageLabelPad
+
+	strip := image.NewRGBA(image.Rect(0, 0, len(label)*glyphWidth+2*montageLabelPad, stripHeight))
+	draw.Draw(strip, strip.Bounds(), &image.Uniform{C: montageBackground}, image.Point{}, draw.Src)
+	for i, ch := range label {
+		glyph := montageGlyphs[ch]
+		left := montageLabelPad + i*glyphWidth
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				x := left + col*montageLabelScale
+				y := montageLabelPad + row*montageLabelScale
+				draw.Draw(strip, image.Rect(x, y, x+montageLabelScale, y+montageLabelScale),
+					&image.Uniform{C: montageLabelColor}, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return StackVertical([]*image.RGBA{strip, frame.Image}, 0)
+}
+
+// SaveMontage stacks the frames, oldest first, each labeled with its attempt
+// number, and saves the result as montage.png in the output folder
+func SaveMontage(frames []MontageFrame) (string, error) {
+	if len(frames) == 0 {
+		return "", fmt.Errorf("no frames for the montage")
+	}
+
+	labeled := make([]*image.RGBA, 0, len(frames))
+	for _, frame := range frames {
+		labeled = append(labeled, labelFrame(frame))
+	}
+	montage := StackVertical(labeled, montageGap)
+
+	tempDir := OutputDir()
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+
+	filename := filepath.Join(tempDir, "montage.png")
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create montage file: %v", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, montage); err != nil {
+		return "", fmt.Errorf("failed to encode montage: %v", err)
+	}
+	return filename, nil
+}
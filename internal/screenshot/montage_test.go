@@ -0,0 +1,93 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// filled returns a width x height image of one color
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestStackVertical(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	stacked := StackVertical([]*image.RGBA{filled(10, 3, red), filled(6, 5, green), filled(8, 2, blue)}, 4)
+	if got, want := stacked.Bounds(), image.Rect(0, 0, 10, 3+4+5+4+2); got != want {
+		t.Fatalf("bounds = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"first image on top", 0, 0, red},
+		{"first image last row", 9, 2, red},
+		{"gap after it", 0, 3, montageBackground},
+		{"second image", 0, 7, green},
+		{"right of a narrower image", 7, 7, montageBackground},
+		{"third image at the bottom", 7, 17, blue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stacked.RGBAAt(tt.x, tt.y); got != tt.want {
+				t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+
+	if empty := StackVertical(nil, 4); !empty.Bounds().Empty() {
+		t.Errorf("stacking nothing gave %v, want an empty image", empty.Bounds())
+	}
+}
+
+func TestSaveMontage(t *testing.T) {
+	defer SetOutputDir(OutputDir())
+	SetOutputDir(t.TempDir())
+
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	frames := []MontageFrame{
+		{Attempt: 7, Image: filled(40, 10, red)},
+		{Attempt: 12, Image: filled(40, 10, blue)},
+	}
+
+	filename, err := SaveMontage(frames)
+	if err != nil {
+		t.Fatalf("SaveMontage() error = %v", err)
+	}
+	montage, err := LoadImage(filename)
+	if err != nil {
+		t.Fatalf("LoadImage() error = %v", err)
+	}
+
+	// Each frame gets a label strip above it
+	stripHeight := 5*montageLabelScale + 2*montageLabelPad
+	frameHeight := stripHeight + 10
+	if got, want := montage.Bounds().Dy(), 2*frameHeight+montageGap; got != want {
+		t.Fatalf("montage height = %d, want %d", got, want)
+	}
+	if got := montage.RGBAAt(0, stripHeight); got != red {
+		t.Errorf("first frame = %v, want the oldest (red)", got)
+	}
+	if got := montage.RGBAAt(0, frameHeight+montageGap+stripHeight); got != blue {
+		t.Errorf("second frame = %v, want the newest (blue)", got)
+	}
+	if got := montage.RGBAAt(montageLabelPad, montageLabelPad); got != montageLabelColor {
+		t.Errorf("label pixel = %v, want the label color", got)
+	}
+
+	if _, err := SaveMontage(nil); err == nil {
+		t.Error("SaveMontage(nil) returned no error")
+	}
+}
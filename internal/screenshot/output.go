@@ -0,0 +1,18 @@
+package screenshot
+
+// outputDir is where debug, milestone and montage images are written
+var outputDir = "temp"
+
+// SetOutputDir changes where images are written, e.g. to a per-session
+// folder; an empty dir restores the default temp folder
+func SetOutputDir(dir string) {
+	if dir == "" {
+		dir = "temp"
+	}
+	outputDir = dir
+}
+
+// OutputDir returns the folder images are written to
+func OutputDir() string {
+	return outputDir
+}
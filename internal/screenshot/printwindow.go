@@ -0,0 +1,162 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"unsafe"
+
+	"maple_flame/internal/window"
+)
+
+var (
+	procPrintWindow   = user32.NewProc("PrintWindow")
+	procGetWindowRect = user32.NewProc("GetWindowRect")
+)
+
+// PW_RENDERFULLCONTENT makes PrintWindow capture DirectX/DWM-composed
+// content, which the game window needs to render anything but black
+const PW_RENDERFULLCONTENT = 0x00000002
+
+// backgroundCapture makes CaptureScreenRegion read the game window through
+// PrintWindow, so it works while the window is covered by other windows
+var backgroundCapture = false
+
+// SetBackgroundCapture turns PrintWindow capture on or off
+func SetBackgroundCapture(on bool) {
+	backgroundCapture = on
+}
+
+// CaptureScreenRegion captures a region at an offset from the window (or
+// the screen center or client area). With background capture on and the
+// window or client anchor it reads the window's own content; otherwise it
+// copies what is on screen.
+func CaptureScreenRegion(windowRect *window.WindowRect, regionX, regionY, width, height int) (*image.RGBA, error) {
+	if backgroundCapture && window.CurrentAnchor() != window.AnchorScreenCenter {
+		if hwnd, err := window.TargetHandle(); err == nil {
+			return captureBackground(hwnd, windowRect, regionX, regionY, width, height)
+		}
+	}
+	return captureScreenBitBlt(windowRect, regionX, regionY, width, height)
+}
+
+// CaptureHandleRegion is CaptureRegion for a window already found, reusing
+// its handle for background capture instead of searching for the window. A
+// nil handle captures like CaptureRegion with no window.
+func CaptureHandleRegion(handle *window.WindowHandle, region Region) (*image.RGBA, error) {
+	if handle == nil {
+		return CaptureRegion(nil, region)
+	}
+	if backgroundCapture && window.CurrentAnchor() != window.AnchorScreenCenter {
+		return captureBackground(handle.HWND, &handle.Rect, region.X, region.Y, region.Width, region.Height)
+	}
+	return captureScreenBitBlt(&handle.Rect, region.X, region.Y, region.Width, region.Height)
+}
+
+// captureBackground reads a region of hwnd through PrintWindow, moving it
+// past the title bar and borders for the client anchor
+func captureBackground(hwnd uintptr, windowRect *window.WindowRect, regionX, regionY, width, height int) (*image.RGBA, error) {
+	if window.CurrentAnchor() == window.AnchorClient {
+		dx, dy, err := window.ClientOffset(hwnd)
+		if err != nil {
+			return captureScreenBitBlt(windowRect, regionX, regionY, width, height)
+		}
+		regionX, regionY = regionX+dx, regionY+dy
+	}
+	return CaptureWindowClientArea(hwnd, regionX, regionY, width, height)
+}
+
+// CaptureWindowClientArea captures a region of a window, relative to its
+// top-left corner, with PrintWindow so it works even when the window is
+// covered. If PrintWindow fails it falls back to copying the screen.
+func CaptureWindowClientArea(hwnd uintptr, regionX, regionY, width, height int) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid capture size %dx%d: width and height must be positive", width, height)
+	}
+
+	var rect window.WindowRect
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect))); ret == 0 {
+		return nil, fmt.Errorf("failed to get window rectangle")
+	}
+	windowWidth := int(rect.Right - rect.Left)
+	windowHeight := int(rect.Bottom - rect.Top)
+	if regionX < 0 || regionY < 0 || regionX+width > windowWidth || regionY+height > windowHeight {
+		return nil, fmt.Errorf("capture region %dx%d at (%d,%d) is outside the %dx%d window", width, height, regionX, regionY, windowWidth, windowHeight)
+	}
+
+	full, ok, err := printWindow(hwnd, windowWidth, windowHeight)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return captureScreenBitBlt(&rect, regionX, regionY, width, height)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		src := full.Pix[full.PixOffset(regionX, regionY+y):]
+		copy(img.Pix[y*img.Stride:y*img.Stride+width*4], src[:width*4])
+	}
+	return img, nil
+}
+
+// printWindow renders the whole window into an image. ok is false when
+// PrintWindow itself fails, so the caller can fall back to the screen.
+func printWindow(hwnd uintptr, width, height int) (*image.RGBA, bool, error) {
+	hdcScreen, _, _ := procGetDC.Call(0)
+	if hdcScreen == 0 {
+		return nil, false, fmt.Errorf("failed to get DC for screen")
+	}
+	defer procReleaseDC.Call(0, hdcScreen)
+
+	hdcMem, _, _ := procCreateCompatibleDC.Call(hdcScreen)
+	if hdcMem == 0 {
+		return nil, false, fmt.Errorf("failed to create compatible DC")
+	}
+	defer procDeleteDC.Call(hdcMem)
+
+	hBitmap, _, _ := procCreateCompatibleBitmap.Call(hdcScreen, uintptr(width), uintptr(height))
+	if hBitmap == 0 {
+		return nil, false, fmt.Errorf("failed to create compatible bitmap")
+	}
+	defer procDeleteObject.Call(hBitmap)
+
+	procSelectObject.Call(hdcMem, hBitmap)
+
+	if ret, _, _ := procPrintWindow.Call(hwnd, hdcMem, PW_RENDERFULLCONTENT); ret == 0 {
+		return nil, false, nil
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bmi := bitmapInfoHeader{
+		Size:     uint32(unsafe.Sizeof(bitmapInfoHeader{})),
+		Width:    int32(width),
+		Height:   -int32(height), // Negative height for top-down DIB
+		Planes:   1,
+		BitCount: 32,
+	}
+	procGetDIBits.Call(
+		hdcMem,
+		hBitmap,
+		0,
+		uintptr(height),
+		uintptr(unsafe.Pointer(&img.Pix[0])),
+		uintptr(unsafe.Pointer(&bmi)),
+		0, // DIB_RGB_COLORS
+	)
+	return img, true, nil
+}
+
+// bitmapInfoHeader is the Windows BITMAPINFOHEADER used with GetDIBits
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
@@ -0,0 +1,78 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"maple_flame/internal/window"
+)
+
+// Region is a rectangle relative to the MapleStory window
+type Region struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// ParseRegion parses an "x,y,width,height" region spec
+func ParseRegion(spec string) (Region, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 4 {
+		return Region{}, fmt.Errorf("invalid region %q: expected x,y,width,height", spec)
+	}
+
+	values := make([]int, 4)
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Region{}, fmt.Errorf("invalid region %q: %q is not a number", spec, part)
+		}
+		values[i] = value
+	}
+
+	region := Region{X: values[0], Y: values[1], Width: values[2], Height: values[3]}
+	if region.Width <= 0 || region.Height <= 0 {
+		return Region{}, fmt.Errorf("invalid region %q: width and height must be positive", spec)
+	}
+	return region, nil
+}
+
+// IsZero reports whether the region is unset
+func (r Region) IsZero() bool {
+	return r == Region{}
+}
+
+// String formats the region the way ParseRegion reads it
+func (r Region) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+}
+
+// CenteredRegion returns a size x size region centered on (x, y)
+func CenteredRegion(x, y, size int) Region {
+	return Region{X: x - size/2, Y: y - size/2, Width: size, Height: size}
+}
+
+// CaptureRegion captures a Region of the window
+func CaptureRegion(windowRect *window.WindowRect, region Region) (*image.RGBA, error) {
+	return CaptureScreenRegion(windowRect, region.X, region.Y, region.Width, region.Height)
+}
+
+// SamplePixel returns the color of a single pixel at an offset from the window's top-left corner
+func SamplePixel(handle *window.WindowHandle, x, y int) (color.RGBA, error) {
+	img, err := CaptureHandleRegion(handle, Region{X: x, Y: y, Width: 1, Height: 1})
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return capturedColor(img, 0, 0), nil
+}
+
+// capturedColor returns the true color of a captured pixel. GetDIBits fills
+// BGRA, so red and blue are swapped and the unused alpha byte is ignored.
+func capturedColor(img *image.RGBA, x, y int) color.RGBA {
+	pixel := img.RGBAAt(x, y)
+	return color.RGBA{R: pixel.B, G: pixel.G, B: pixel.R, A: 255}
+}
@@ -0,0 +1,57 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCapturedColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	// Stored the way GetDIBits writes them: blue, green, red, unused
+	copy(img.Pix, []byte{0x30, 0x20, 0x10, 0x00, 0xFF, 0x80, 0x00, 0x7F})
+
+	tests := []struct {
+		name string
+		x    int
+		want color.RGBA
+	}{
+		{"dark pixel", 0, color.RGBA{0x10, 0x20, 0x30, 255}},
+		{"orange pixel", 1, color.RGBA{0x00, 0x80, 0xFF, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capturedColor(img, tt.x, 0); got != tt.want {
+				t.Errorf("capturedColor(%d, 0) = %v, want %v", tt.x, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCenteredRegion(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y int
+		size int
+		want Region
+	}{
+		{"even size", 1150, 600, 50, Region{X: 1125, Y: 575, Width: 50, Height: 50}},
+		{"odd size", 100, 40, 9, Region{X: 96, Y: 36, Width: 9, Height: 9}},
+		{"single pixel", 10, 20, 1, Region{X: 10, Y: 20, Width: 1, Height: 1}},
+		{"near the window corner", 5, 3, 50, Region{X: -20, Y: -22, Width: 50, Height: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CenteredRegion(tt.x, tt.y, tt.size)
+			if got != tt.want {
+				t.Errorf("CenteredRegion(%d, %d, %d) = %v, want %v", tt.x, tt.y, tt.size, got, tt.want)
+			}
+			// The click lands on the center pixel of the saved image
+			if cx, cy := tt.x-got.X, tt.y-got.Y; cx != tt.size/2 || cy != tt.size/2 {
+				t.Errorf("click at (%d,%d) in the region, want (%d,%d)", cx, cy, tt.size/2, tt.size/2)
+			}
+		})
+	}
+}
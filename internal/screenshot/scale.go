@@ -0,0 +1,46 @@
+package screenshot
+
+import "fmt"
+
+// DefaultMaxEnhancedSize is the default cap on the width and height of an
+// upscaled OCR image
+const DefaultMaxEnhancedSize = 4000
+
+// maxEnhancedSize caps the width and height of upscaled OCR images, so a
+// large capture region doesn't turn into a huge, slow image; 0 means no cap
+var maxEnhancedSize = DefaultMaxEnhancedSize
+
+// scaleCapLogged keeps the cap message to once per run
+var scaleCapLogged = false
+
+// SetMaxEnhancedSize changes the cap on upscaled OCR image dimensions; 0
+// turns it off
+func SetMaxEnhancedSize(size int) {
+	maxEnhancedSize = size
+}
+
+// CapScaleFactor returns the largest scale, at most scaleFactor, that keeps
+// a width x height image within the size cap once upscaled. It never returns
+// less than 1, so an image already over the cap is left at its own size.
+func CapScaleFactor(width, height, scaleFactor int) int {
+	scale := capScale(width, height, scaleFactor, maxEnhancedSize)
+	if scale < scaleFactor && !scaleCapLogged {
+		scaleCapLogged = true
+		fmt.Printf("ℹ️  %dx%d OCR image would exceed %dpx at %dx, upscaling %dx instead\n",
+			width, height, maxEnhancedSize, scaleFactor, scale)
+	}
+	return scale
+}
+
+// capScale is CapScaleFactor with the cap passed in
+func capScale(width, height, scaleFactor, maxSize int) int {
+	if maxSize <= 0 {
+		return scaleFactor
+	}
+
+	scale := scaleFactor
+	for scale > 1 && (width*scale > maxSize || height*scale > maxSize) {
+		scale--
+	}
+	return scale
+}
@@ -0,0 +1,28 @@
+package screenshot
+
+import "testing"
+
+func TestCapScale(t *testing.T) {
+	tests := []struct {
+		name                 string
+		width, height, scale int
+		maxSize              int
+		want                 int
+	}{
+		{"within the cap", 300, 100, 4, 4000, 4},
+		{"width over the cap", 1200, 100, 4, 4000, 3},
+		{"height over the cap", 100, 1500, 4, 4000, 2},
+		{"exactly the cap", 1000, 1000, 4, 4000, 4},
+		{"already over the cap", 5000, 100, 4, 4000, 1},
+		{"no cap", 5000, 5000, 4, 0, 4},
+		{"scale 1", 300, 100, 1, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capScale(tt.width, tt.height, tt.scale, tt.maxSize); got != tt.want {
+				t.Errorf("capScale(%d, %d, %d, %d) = %d, want %d", tt.width, tt.height, tt.scale, tt.maxSize, got, tt.want)
+			}
+		})
+	}
+}
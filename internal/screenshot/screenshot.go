@@ -32,11 +32,17 @@ const (
 	SRCCOPY = 0x00CC0020
 )
 
-// CaptureScreenRegion captures a specific region of the screen
-func CaptureScreenRegion(windowRect *window.WindowRect, regionX, regionY, width, height int) (*image.RGBA, error) {
-	// Calculate absolute coordinates
-	x := int(windowRect.Left) + regionX
-	y := int(windowRect.Top) + regionY
+// captureScreenBitBlt captures a specific region of the screen
+func captureScreenBitBlt(windowRect *window.WindowRect, regionX, regionY, width, height int) (*image.RGBA, error) {
+	// An empty region would create an invalid bitmap and an empty pixel buffer
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid capture size %dx%d: width and height must be positive", width, height)
+	}
+
+	// Calculate absolute coordinates from the window (or screen center)
+	originX, originY := window.Origin(windowRect)
+	x := originX + regionX
+	y := originY + regionY
 
 	// Get device context for entire screen
 	hdcScreen, _, _ := procGetDC.Call(0)
@@ -119,7 +125,7 @@ const maxScreenshots = 7
 // and maintains a FIFO queue of screenshots (max 7)
 func SaveDebugImage(img *image.RGBA, tryNumber int) (string, error) {
 	// Create temp directory if it doesn't exist
-	tempDir := filepath.Join(".", "temp")
+	tempDir := OutputDir()
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %v", err)
 	}
@@ -156,7 +162,7 @@ func SaveDebugImage(img *image.RGBA, tryNumber int) (string, error) {
 // Used for flame scoring to distinguish between "before" and "after" images
 func SaveDebugImageWithPrefix(img *image.RGBA, prefix string, tryNumber int) (string, error) {
 	// Create temp directory if it doesn't exist
-	tempDir := filepath.Join(".", "temp")
+	tempDir := OutputDir()
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %v", err)
 	}
@@ -189,6 +195,39 @@ func SaveDebugImageWithPrefix(img *image.RGBA, prefix string, tryNumber int) (st
 	return filename, nil
 }
 
+// IsMilestone reports whether an attempt should get a milestone screenshot
+// when milestones are taken every `every` attempts (0 disables them)
+func IsMilestone(attempt, every int) bool {
+	return every > 0 && attempt > 0 && attempt%every == 0
+}
+
+// SaveMilestoneImage saves a permanent screenshot tagged with the attempt number and score.
+// Unlike the debug screenshots these are never cleaned up.
+func SaveMilestoneImage(img *image.RGBA, attempt, score int) (string, error) {
+	// Create temp directory if it doesn't exist
+	tempDir := OutputDir()
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+
+	// Create filename with attempt number and score
+	filename := filepath.Join(tempDir, fmt.Sprintf("milestone_%d_score_%d.png", attempt, score))
+
+	// Create file
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create milestone image file: %v", err)
+	}
+	defer f.Close()
+
+	// Encode and save
+	if err := png.Encode(f, img); err != nil {
+		return "", fmt.Errorf("failed to encode milestone image: %v", err)
+	}
+
+	return filename, nil
+}
+
 // CombineImagesHorizontal combines two images side by side (left + right)
 // Used specifically for flame scoring to show before/after comparison
 func CombineImagesHorizontal(leftImg, rightImg *image.RGBA, tryNumber int) (string, error) {
@@ -221,7 +260,7 @@ func CombineImagesHorizontal(leftImg, rightImg *image.RGBA, tryNumber int) (stri
 	}
 	
 	// Create temp directory if it doesn't exist
-	tempDir := filepath.Join(".", "temp")
+	tempDir := OutputDir()
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %v", err)
 	}
@@ -257,7 +296,7 @@ func CombineImagesHorizontal(leftImg, rightImg *image.RGBA, tryNumber int) (stri
 // CombineEnhancedImages loads enhanced images from disk and combines them
 // This is used to combine the OCR-enhanced versions of the images
 func CombineEnhancedImages(tryNumber int) (string, error) {
-	tempDir := filepath.Join(".", "temp")
+	tempDir := OutputDir()
 	
 	// Load the enhanced images
 	beforePath := filepath.Join(tempDir, fmt.Sprintf("temp_before_%d_enhanced.png", tryNumber))
@@ -340,6 +379,9 @@ func EnhanceImageForOCR(img *image.RGBA, scaleFactor int) *image.RGBA {
 	originalWidth := bounds.Dx()
 	originalHeight := bounds.Dy()
 	
+	// Keep large regions from producing oversized images
+	scaleFactor = CapScaleFactor(originalWidth, originalHeight, scaleFactor)
+	
 	newWidth := originalWidth * scaleFactor
 	newHeight := originalHeight * scaleFactor
 	
@@ -436,40 +478,32 @@ func applySharpeningFilter(img *image.RGBA) *image.RGBA {
 
 // enhanceContrast enhances contrast to make text more readable
 func enhanceContrast(img *image.RGBA) *image.RGBA {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			pixel := img.RGBAAt(x, y)
-			
-			// Convert to grayscale for better text recognition
-			gray := uint8((uint16(pixel.R)*299 + uint16(pixel.G)*587 + uint16(pixel.B)*114) / 1000)
-			
-			// Apply contrast enhancement - make bright pixels brighter, dark pixels darker
-			var enhanced uint8
-			if gray > 128 {
-				// Bright pixels - make brighter
-				brightened := float64(gray) * 1.2
-				if brightened > 255 {
-					enhanced = 255
-				} else {
-					enhanced = uint8(brightened)
-				}
+	// Convert to grayscale for better text recognition
+	gray := ToGrayscaleFast(img)
+	result := image.NewRGBA(gray.Bounds())
+
+	for i, value := range gray.Pix {
+		// Apply contrast enhancement - make bright pixels brighter, dark pixels darker
+		var enhanced uint8
+		if value > 128 {
+			// Bright pixels - make brighter
+			brightened := float64(value) * 1.2
+			if brightened > 255 {
+				enhanced = 255
 			} else {
-				// Dark pixels - make darker
-				enhanced = uint8(float64(gray)*0.8)
+				enhanced = uint8(brightened)
 			}
-			
-			result.Set(x, y, color.RGBA{
-				R: enhanced,
-				G: enhanced,
-				B: enhanced,
-				A: 255,
-			})
+		} else {
+			// Dark pixels - make darker
+			enhanced = uint8(float64(value) * 0.8)
 		}
+
+		result.Pix[i*4] = enhanced
+		result.Pix[i*4+1] = enhanced
+		result.Pix[i*4+2] = enhanced
+		result.Pix[i*4+3] = 255
 	}
-	
+
 	return result
 }
 
@@ -479,16 +513,17 @@ func LightEnhanceForOCR(img *image.RGBA) *image.RGBA {
 	originalWidth := bounds.Dx()
 	originalHeight := bounds.Dy()
 	
-	// 2x upscale using nearest neighbor
-	newWidth := originalWidth * 2
-	newHeight := originalHeight * 2
+	// 2x upscale using nearest neighbor, unless that would exceed the size cap
+	scaleFactor := CapScaleFactor(originalWidth, originalHeight, 2)
+	newWidth := originalWidth * scaleFactor
+	newHeight := originalHeight * scaleFactor
 	
 	enlarged := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
 	
 	for y := 0; y < newHeight; y++ {
 		for x := 0; x < newWidth; x++ {
-			origX := x / 2
-			origY := y / 2
+			origX := x / scaleFactor
+			origY := y / scaleFactor
 			
 			if origX >= originalWidth {
 				origX = originalWidth - 1
@@ -563,4 +598,35 @@ func lightSharpen(img *image.RGBA) *image.RGBA {
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+// Margins describes how many pixels to trim from each edge of an image
+type Margins struct {
+	Top    int
+	Bottom int
+	Left   int
+	Right  int
+}
+
+// Crop trims the given margins from an image, returning a new image whose
+// origin is (0,0). Margins that would remove the whole image are ignored.
+func Crop(img *image.RGBA, margins Margins) *image.RGBA {
+	bounds := img.Bounds()
+	cropped := image.Rect(
+		bounds.Min.X+margins.Left,
+		bounds.Min.Y+margins.Top,
+		bounds.Max.X-margins.Right,
+		bounds.Max.Y-margins.Bottom,
+	)
+	if margins == (Margins{}) || cropped.Empty() || !cropped.In(bounds) {
+		return img
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, cropped.Dx(), cropped.Dy()))
+	for y := 0; y < cropped.Dy(); y++ {
+		for x := 0; x < cropped.Dx(); x++ {
+			result.SetRGBA(x, y, img.RGBAAt(cropped.Min.X+x, cropped.Min.Y+y))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,128 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"maple_flame/internal/window"
+)
+
+func TestCrop(t *testing.T) {
+	// Each pixel encodes its own position so moved content is easy to spot
+	src := image.NewRGBA(image.Rect(0, 0, 10, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 10; x++ {
+			src.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	tests := []struct {
+		name          string
+		margins       Margins
+		width, height int
+	}{
+		{"no margins", Margins{}, 10, 6},
+		{"all sides", Margins{Top: 1, Bottom: 2, Left: 3, Right: 1}, 6, 3},
+		{"top only", Margins{Top: 2}, 10, 4},
+		{"left and right", Margins{Left: 4, Right: 4}, 2, 6},
+		{"nothing left", Margins{Left: 5, Right: 5}, 10, 6},
+		{"negative margin", Margins{Top: -1}, 10, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Crop(src, tt.margins)
+			if got.Bounds().Dx() != tt.width || got.Bounds().Dy() != tt.height {
+				t.Fatalf("Crop() size = %dx%d, want %dx%d", got.Bounds().Dx(), got.Bounds().Dy(), tt.width, tt.height)
+			}
+			if got == src {
+				return // margins were ignored, the source is returned as is
+			}
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					want := src.RGBAAt(x+tt.margins.Left, y+tt.margins.Top)
+					if c := got.RGBAAt(x, y); c != want {
+						t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, c, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIsMilestone(t *testing.T) {
+	tests := []struct {
+		name  string
+		every int
+		want  []int // milestone attempts among 0..12
+	}{
+		{"off", 0, nil},
+		{"negative", -3, nil},
+		{"every attempt", 1, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"every fifth", 5, []int{5, 10}},
+		{"longer than the run", 20, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for attempt := 0; attempt <= 12; attempt++ {
+				if IsMilestone(attempt, tt.every) {
+					got = append(got, attempt)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("milestones = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveMilestoneImage(t *testing.T) {
+	defer SetOutputDir(OutputDir())
+	dir := t.TempDir()
+	SetOutputDir(dir)
+
+	filename, err := SaveMilestoneImage(image.NewRGBA(image.Rect(0, 0, 4, 4)), 50, 3)
+	if err != nil {
+		t.Fatalf("SaveMilestoneImage() error = %v", err)
+	}
+	if want := filepath.Join(dir, "milestone_50_score_3.png"); filename != want {
+		t.Errorf("SaveMilestoneImage() = %q, want %q", filename, want)
+	}
+	if _, err := os.Stat(filename); err != nil {
+		t.Errorf("milestone file not written: %v", err)
+	}
+}
+
+func TestCaptureRejectsEmptySize(t *testing.T) {
+	rect := &window.WindowRect{Left: 0, Top: 0, Right: 800, Bottom: 600}
+
+	tests := []struct {
+		name          string
+		width, height int
+	}{
+		{"zero width", 0, 100},
+		{"zero height", 300, 0},
+		{"both zero", 0, 0},
+		{"negative width", -50, 100},
+		{"negative height", 300, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := CaptureScreenRegion(rect, 10, 10, tt.width, tt.height)
+			if err == nil || img != nil {
+				t.Errorf("CaptureScreenRegion(%dx%d) = %v, %v, want an error", tt.width, tt.height, img, err)
+			}
+			img, err = CaptureWindowClientArea(0, 10, 10, tt.width, tt.height)
+			if err == nil || img != nil {
+				t.Errorf("CaptureWindowClientArea(%dx%d) = %v, %v, want an error", tt.width, tt.height, img, err)
+			}
+		})
+	}
+}
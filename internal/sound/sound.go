@@ -0,0 +1,50 @@
+// Package sound plays notification beeps and WAV files
+package sound
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	kernel32       = syscall.NewLazyDLL("kernel32.dll")
+	winmm          = syscall.NewLazyDLL("winmm.dll")
+	procBeep       = kernel32.NewProc("Beep")
+	procPlaySoundW = winmm.NewProc("PlaySoundW")
+)
+
+// PlaySound flags
+const (
+	SND_SYNC      = 0x00000000
+	SND_NODEFAULT = 0x00000002
+	SND_FILENAME  = 0x00020000
+)
+
+// Beep plays a tone of the given frequency (37-32767 Hz) and blocks until it
+// ends. A frequency of 0 is a silent pause of the same length.
+func Beep(frequency int, duration time.Duration) error {
+	if frequency == 0 {
+		time.Sleep(duration)
+		return nil
+	}
+	ret, _, err := procBeep.Call(uintptr(frequency), uintptr(duration.Milliseconds()))
+	if ret == 0 {
+		return fmt.Errorf("Beep failed: %v", err)
+	}
+	return nil
+}
+
+// PlayWAV plays a WAV file and blocks until it ends
+func PlayWAV(path string) error {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return fmt.Errorf("invalid sound file path %q: %v", path, err)
+	}
+	ret, _, _ := procPlaySoundW.Call(uintptr(unsafe.Pointer(pathPtr)), 0, SND_FILENAME|SND_SYNC|SND_NODEFAULT)
+	if ret == 0 {
+		return fmt.Errorf("could not play %s", path)
+	}
+	return nil
+}
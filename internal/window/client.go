@@ -0,0 +1,81 @@
+package window
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+var (
+	procGetClientRect  = user32.NewProc("GetClientRect")
+	procClientToScreen = user32.NewProc("ClientToScreen")
+)
+
+// point mirrors the Win32 POINT struct
+type point struct {
+	X int32
+	Y int32
+}
+
+// GetClientRect returns a window's client area, the part inside the title bar
+// and borders, in screen coordinates. Unlike the outer rectangle, its
+// top-left corner stays on the game's content whether the window is
+// maximized, windowed or borderless.
+func GetClientRect(hwnd uintptr) (*WindowRect, error) {
+	var client WindowRect
+	if ret, _, _ := procGetClientRect.Call(hwnd, uintptr(unsafe.Pointer(&client))); ret == 0 {
+		return nil, fmt.Errorf("failed to get client rectangle")
+	}
+
+	// GetClientRect is relative to the client area itself, so Left/Top are
+	// always 0; ClientToScreen finds where that corner is on screen
+	var origin point
+	if ret, _, _ := procClientToScreen.Call(hwnd, uintptr(unsafe.Pointer(&origin))); ret == 0 {
+		return nil, fmt.Errorf("failed to convert client origin to screen coordinates")
+	}
+
+	return &WindowRect{
+		Left:   origin.X,
+		Top:    origin.Y,
+		Right:  origin.X + client.Right,
+		Bottom: origin.Y + client.Bottom,
+	}, nil
+}
+
+// ClientOffset returns how far a window's client area starts from its outer
+// top-left corner, i.e. the width of the left border and the height of the
+// title bar plus top border
+func ClientOffset(hwnd uintptr) (dx, dy int, err error) {
+	var outer WindowRect
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&outer))); ret == 0 {
+		return 0, 0, fmt.Errorf("failed to get window rectangle")
+	}
+	client, err := GetClientRect(hwnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(client.Left - outer.Left), int(client.Top - outer.Top), nil
+}
+
+// clientArea returns the target window's client area, falling back to the
+// outer rectangle when it can't be read
+func clientArea(rect *WindowRect) *WindowRect {
+	hwnd, err := findTargetWindow()
+	if err != nil {
+		return rect
+	}
+	client, err := GetClientRect(hwnd)
+	if err != nil {
+		return rect
+	}
+	return client
+}
+
+// ScreenToClient converts a screen position into a position in a window's
+// client area, the coordinates window messages use
+func ScreenToClient(hwnd uintptr, x, y int) (int, int, error) {
+	client, err := GetClientRect(hwnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	return x - int(client.Left), y - int(client.Top), nil
+}
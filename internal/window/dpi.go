@@ -0,0 +1,33 @@
+package window
+
+var (
+	procGetDpiForWindow = user32.NewProc("GetDpiForWindow")
+)
+
+// DefaultDPI is the DPI of an unscaled (100%) display
+const DefaultDPI = 96
+
+// WindowDPI returns the DPI of the display the MapleStory window is on, or
+// DefaultDPI when it can't be read (GetDpiForWindow needs Windows 10 1607)
+func WindowDPI() (int, error) {
+	hwnd, err := findTargetWindow()
+	if err != nil {
+		return DefaultDPI, err
+	}
+	if procGetDpiForWindow.Find() != nil {
+		return DefaultDPI, nil
+	}
+	dpi, _, _ := procGetDpiForWindow.Call(hwnd)
+	if dpi == 0 {
+		return DefaultDPI, nil
+	}
+	return int(dpi), nil
+}
+
+// DPIScale returns the display scaling for a DPI, e.g. 1.5 for 144 (150%)
+func DPIScale(dpi int) float64 {
+	if dpi <= 0 {
+		return 1
+	}
+	return float64(dpi) / DefaultDPI
+}
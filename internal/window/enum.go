@@ -0,0 +1,84 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procEnumWindows          = user32.NewProc("EnumWindows")
+	procGetWindowTextW       = user32.NewProc("GetWindowTextW")
+	procGetWindowTextLengthW = user32.NewProc("GetWindowTextLengthW")
+	procIsWindowVisible      = user32.NewProc("IsWindowVisible")
+)
+
+// windowTitle reads a window's title bar text
+func windowTitle(hwnd uintptr) string {
+	length, _, _ := procGetWindowTextLengthW.Call(hwnd)
+	if length == 0 {
+		return ""
+	}
+	buf := make([]uint16, length+1)
+	procGetWindowTextW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return syscall.UTF16ToString(buf)
+}
+
+// partialSearch is the state of the running FindWindowByPartialTitle call.
+// The enumeration callback is created once, since Windows callbacks made by
+// syscall.NewCallback are never freed and the lookup runs every attempt.
+var (
+	partialSearchMu sync.Mutex
+	partialSearch   struct {
+		needle        string
+		prefixMatch   uintptr
+		containsMatch uintptr
+		scanned       int
+	}
+	enumCallback = syscall.NewCallback(enumWindowsProc)
+)
+
+// enumWindowsProc checks one window for FindWindowByPartialTitle
+func enumWindowsProc(hwnd, _ uintptr) uintptr {
+	search := &partialSearch
+	search.scanned++
+	if visible, _, _ := procIsWindowVisible.Call(hwnd); visible == 0 {
+		return 1 // Keep enumerating
+	}
+	title := strings.ToLower(windowTitle(hwnd))
+	switch {
+	case strings.HasPrefix(title, search.needle):
+		search.prefixMatch = hwnd
+		return 0 // Best possible match, stop
+	case search.containsMatch == 0 && strings.Contains(title, search.needle):
+		search.containsMatch = hwnd
+	}
+	return 1
+}
+
+// FindWindowByPartialTitle enumerates the visible top-level windows and
+// returns the first whose title contains substr, ignoring case. A title that
+// starts with substr wins over one that only contains it, so "MapleStory -
+// Channel 3" is picked before a browser tab about MapleStory.
+func FindWindowByPartialTitle(substr string) (uintptr, error) {
+	partialSearchMu.Lock()
+	defer partialSearchMu.Unlock()
+
+	search := &partialSearch
+	search.needle = strings.ToLower(substr)
+	search.prefixMatch, search.containsMatch, search.scanned = 0, 0, 0
+
+	// EnumWindows reports failure when the callback stops it early, so its
+	// result isn't checked
+	procEnumWindows.Call(enumCallback, 0)
+
+	if search.prefixMatch != 0 {
+		return search.prefixMatch, nil
+	}
+	if search.containsMatch != 0 {
+		return search.containsMatch, nil
+	}
+	return 0, fmt.Errorf("no window title contains %q (%d windows scanned)", substr, search.scanned)
+}
@@ -0,0 +1,58 @@
+package window
+
+// referenceWidth and referenceHeight are the window size the configured
+// offsets were measured at (0 = offsets are used as-is)
+var referenceWidth, referenceHeight int
+
+// SetReferenceSize sets the window size offsets were measured at, so
+// ScaleToWindow can stretch them to the actual window. 0x0 turns scaling off.
+func SetReferenceSize(width, height int) {
+	referenceWidth, referenceHeight = width, height
+}
+
+// ReferenceSize returns the size set with SetReferenceSize (0x0 = off)
+func ReferenceSize() (width, height int) {
+	return referenceWidth, referenceHeight
+}
+
+// ScaleRegion scales a region measured on a refW x refH window to the size
+// of rect, rounding to the nearest pixel. Width and height stay at least 1
+// when they started positive.
+func ScaleRegion(rect *WindowRect, refW, refH, x, y, w, h int) (int, int, int, int) {
+	width := int(rect.Right - rect.Left)
+	height := int(rect.Bottom - rect.Top)
+	if refW <= 0 || refH <= 0 || width <= 0 || height <= 0 {
+		return x, y, w, h
+	}
+
+	scaledW, scaledH := scaleLength(w, width, refW), scaleLength(h, height, refH)
+	if w > 0 && scaledW < 1 {
+		scaledW = 1
+	}
+	if h > 0 && scaledH < 1 {
+		scaledH = 1
+	}
+	return scaleLength(x, width, refW), scaleLength(y, height, refH), scaledW, scaledH
+}
+
+// scaleLength scales v by actual/reference, rounding half away from zero
+func scaleLength(v, actual, reference int) int {
+	scaled := v * actual
+	if scaled < 0 {
+		return -((-scaled + reference/2) / reference)
+	}
+	return (scaled + reference/2) / reference
+}
+
+// ScaleToWindow scales a region from the reference size set with
+// SetReferenceSize to the window (its client area under AnchorClient). With
+// no reference size the region is returned unchanged.
+func ScaleToWindow(rect *WindowRect, x, y, w, h int) (int, int, int, int) {
+	if referenceWidth == 0 || referenceHeight == 0 {
+		return x, y, w, h
+	}
+	if anchor == AnchorClient {
+		rect = clientArea(rect)
+	}
+	return ScaleRegion(rect, referenceWidth, referenceHeight, x, y, w, h)
+}
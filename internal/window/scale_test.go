@@ -0,0 +1,35 @@
+package window
+
+import "testing"
+
+func TestScaleRegion(t *testing.T) {
+	// The stat box measured on a 1280x720 window
+	const refW, refH = 1280, 720
+	const x, y, w, h = 607, 350, 167, 118
+
+	tests := []struct {
+		name                       string
+		rect                       WindowRect
+		refW, refH                 int
+		wantX, wantY, wantW, wantH int
+	}{
+		{"reference size", WindowRect{0, 0, 1280, 720}, refW, refH, 607, 350, 167, 118},
+		{"1920x1080", WindowRect{0, 0, 1920, 1080}, refW, refH, 911, 525, 251, 177},
+		{"1366x768", WindowRect{0, 0, 1366, 768}, refW, refH, 648, 373, 178, 126},
+		{"2560x1440", WindowRect{0, 0, 2560, 1440}, refW, refH, 1214, 700, 334, 236},
+		{"800x600, another aspect ratio", WindowRect{0, 0, 800, 600}, refW, refH, 379, 292, 104, 98},
+		{"position doesn't matter", WindowRect{300, 200, 2220, 1280}, refW, refH, 911, 525, 251, 177},
+		{"tiny window keeps a pixel", WindowRect{0, 0, 5, 3}, refW, refH, 2, 1, 1, 1},
+		{"empty window unchanged", WindowRect{0, 0, 0, 0}, refW, refH, x, y, w, h},
+		{"no reference unchanged", WindowRect{0, 0, 1920, 1080}, 0, 0, x, y, w, h},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotX, gotY, gotW, gotH := ScaleRegion(&tt.rect, tt.refW, tt.refH, x, y, w, h)
+			if gotX != tt.wantX || gotY != tt.wantY || gotW != tt.wantW || gotH != tt.wantH {
+				t.Errorf("ScaleRegion() = %d,%d %dx%d, want %d,%d %dx%d", gotX, gotY, gotW, gotH, tt.wantX, tt.wantY, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
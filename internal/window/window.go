@@ -2,8 +2,10 @@
 package window
 
 import (
+	"errors"
 	"fmt"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -12,8 +14,168 @@ var (
 	procFindWindow        = user32.NewProc("FindWindowW")
 	procGetWindowRect     = user32.NewProc("GetWindowRect")
 	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+	procIsIconic          = user32.NewProc("IsIconic")
+	procShowWindow        = user32.NewProc("ShowWindow")
+	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
+	procGetSystemMetrics  = user32.NewProc("GetSystemMetrics")
+	procIsWindow          = user32.NewProc("IsWindow")
 )
 
+// GetSystemMetrics indexes for the primary screen size
+const (
+	SM_CXSCREEN = 0
+	SM_CYSCREEN = 1
+)
+
+// Anchor is the point capture and click offsets are measured from
+type Anchor int
+
+const (
+	AnchorWindow       Anchor = iota // The window's top-left corner
+	AnchorScreenCenter               // The center of the primary screen, for centered borderless UIs
+	AnchorClient                     // The client area's top-left corner, inside the title bar and borders
+)
+
+// anchor is the basis used by Origin and AbsoluteClickPos
+var anchor = AnchorWindow
+
+// String returns the name ParseAnchor accepts for the anchor
+func (a Anchor) String() string {
+	switch a {
+	case AnchorScreenCenter:
+		return "screen-center"
+	case AnchorClient:
+		return "client"
+	default:
+		return "window"
+	}
+}
+
+// CurrentAnchor returns the anchor set with SetAnchor
+func CurrentAnchor() Anchor {
+	return anchor
+}
+
+// ParseAnchor parses "window", "screen-center" or "client"
+func ParseAnchor(s string) (Anchor, error) {
+	switch s {
+	case "", "window":
+		return AnchorWindow, nil
+	case "screen-center":
+		return AnchorScreenCenter, nil
+	case "client":
+		return AnchorClient, nil
+	default:
+		return AnchorWindow, fmt.Errorf("invalid anchor: %s (valid options: window, screen-center, client)", s)
+	}
+}
+
+// SetAnchor changes the point offsets are measured from
+func SetAnchor(a Anchor) {
+	anchor = a
+}
+
+// screenSize returns the primary screen's size in pixels
+func screenSize() (width, height int) {
+	w, _, _ := procGetSystemMetrics.Call(SM_CXSCREEN)
+	h, _, _ := procGetSystemMetrics.Call(SM_CYSCREEN)
+	return int(w), int(h)
+}
+
+// anchorOrigin returns the screen point offsets are measured from for an
+// anchor, given the window and the screen size
+func anchorOrigin(a Anchor, rect *WindowRect, screenWidth, screenHeight int) (x, y int) {
+	if a == AnchorScreenCenter {
+		return screenWidth / 2, screenHeight / 2
+	}
+	return int(rect.Left), int(rect.Top)
+}
+
+// Origin returns the screen point capture and click offsets are measured
+// from under the configured anchor
+func Origin(rect *WindowRect) (x, y int) {
+	if anchor == AnchorClient {
+		client := clientArea(rect)
+		return int(client.Left), int(client.Top)
+	}
+	if anchor == AnchorWindow {
+		return anchorOrigin(anchor, rect, 0, 0)
+	}
+	width, height := screenSize()
+	return anchorOrigin(anchor, rect, width, height)
+}
+
+// focusPollInterval is how often WaitForForeground checks the foreground window
+const focusPollInterval = 10 * time.Millisecond
+
+// ShowWindow commands for bringing back a minimized window
+const (
+	SW_SHOWNOACTIVATE = 4 // Restore to the last size and position without activating
+	SW_RESTORE        = 9 // Restore to the last size and position and activate
+)
+
+// ErrMinimized is returned when the game window is found but minimized; its
+// rectangle is then an off-screen placeholder like (-32000,-32000) and every
+// capture comes out black
+var ErrMinimized = errors.New("window is minimized")
+
+// ErrCancelled is returned when a wait for the window is called off
+var ErrCancelled = errors.New("wait for the window cancelled")
+
+// DefaultTitle is the window title searched for when none is configured
+const DefaultTitle = "MapleStory"
+
+// targetTitle is the window title passed to FindWindowW
+var targetTitle = DefaultTitle
+
+// SetTargetTitle changes the window title searched for. Any Unicode title is
+// accepted; an empty title restores the default.
+func SetTargetTitle(title string) {
+	if title == "" {
+		title = DefaultTitle
+	}
+	targetTitle = title
+}
+
+// TargetTitle returns the window title being searched for
+func TargetTitle() string {
+	return targetTitle
+}
+
+// findTargetWindow looks up the configured window title and returns its
+// handle, trying an exact match first and then a partial one. It searches
+// every time; keep the WindowHandle from GetMaplestoryWindowHandle to avoid
+// that in a loop.
+func findTargetWindow() (uintptr, error) {
+	// UTF16PtrFromString reports embedded NULs as an error instead of panicking
+	titlePtr, err := syscall.UTF16PtrFromString(targetTitle)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window title %q: %v", targetTitle, err)
+	}
+
+	hwnd, _, _ := procFindWindow.Call(
+		0,
+		uintptr(unsafe.Pointer(titlePtr)),
+	)
+
+	// The title often carries the channel or server name after it, so fall
+	// back to a partial match
+	if hwnd == 0 {
+		partial, err := FindWindowByPartialTitle(targetTitle)
+		if err != nil {
+			return 0, fmt.Errorf("%s window not found: %v", targetTitle, err)
+		}
+		hwnd = partial
+	}
+
+	return hwnd, nil
+}
+
+// TargetHandle returns the handle of the configured game window
+func TargetHandle() (uintptr, error) {
+	return findTargetWindow()
+}
+
 // WindowRect represents a window rectangle
 type WindowRect struct {
 	Left   int32
@@ -22,51 +184,235 @@ type WindowRect struct {
 	Bottom int32
 }
 
-// GetMaplestoryWindow finds the MapleStory window and returns its rectangle
-func GetMaplestoryWindow() (*WindowRect, error) {
+// WindowHandle bundles a found window's handle with its rectangle, so
+// callers can activate or capture it without finding it again. Find it once
+// and pass it along rather than searching on every attempt.
+type WindowHandle struct {
+	HWND uintptr
+	Rect WindowRect
+}
+
+// Bounds returns the window's rectangle, or nil for a nil handle (demo mode)
+func (h *WindowHandle) Bounds() *WindowRect {
+	if h == nil {
+		return nil
+	}
+	return &h.Rect
+}
+
+// Valid reports whether the window still exists; it doesn't once the game
+// has been closed
+func (h *WindowHandle) Valid() bool {
+	valid, _, _ := procIsWindow.Call(h.HWND)
+	return valid != 0
+}
+
+// Minimized reports whether the window is minimized
+func (h *WindowHandle) Minimized() bool {
+	return IsMinimized(h.HWND)
+}
+
+// Activate brings the window to the foreground
+func (h *WindowHandle) Activate() error {
+	if ret, _, _ := procSetForegroundWindow.Call(h.HWND); ret == 0 {
+		return fmt.Errorf("failed to activate %s window", targetTitle)
+	}
+	return nil
+}
+
+// RestoreIfMinimized restores the window if it is minimized, so it can be
+// captured without taking focus. It reports whether a restore was needed.
+func (h *WindowHandle) RestoreIfMinimized() (bool, error) {
+	if !h.Minimized() {
+		return false, nil
+	}
+
+	procShowWindow.Call(h.HWND, SW_SHOWNOACTIVATE)
+
+	// ShowWindow returns the previous visibility, so check the result directly
+	if h.Minimized() {
+		return false, fmt.Errorf("failed to restore minimized %s window", targetTitle)
+	}
+	return true, nil
+}
+
+// GetMaplestoryWindowHandle finds the MapleStory window and returns its
+// handle and rectangle. It only reads; activation is left to
+// FindAndActivateMaplestory so focus isn't taken until a click needs it.
+func GetMaplestoryWindowHandle() (*WindowHandle, error) {
 	// Find the MapleStory window
-	hwnd, _, _ := procFindWindow.Call(
-		0,
-		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("MapleStory"))),
-	)
+	hwnd, err := findTargetWindow()
+	if err != nil {
+		return nil, err
+	}
 
-	if hwnd == 0 {
-		return nil, fmt.Errorf("MapleStory window not found")
+	if IsMinimized(hwnd) {
+		return nil, fmt.Errorf("%s %w - please restore it", targetTitle, ErrMinimized)
 	}
 
 	// Get the window rectangle
-	var rect WindowRect
+	handle := &WindowHandle{HWND: hwnd}
 	ret, _, _ := procGetWindowRect.Call(
 		hwnd,
-		uintptr(unsafe.Pointer(&rect)),
+		uintptr(unsafe.Pointer(&handle.Rect)),
 	)
 
 	if ret == 0 {
 		return nil, fmt.Errorf("failed to get window rectangle")
 	}
 
-	// Activate the window
-	procSetForegroundWindow.Call(hwnd)
+	return handle, nil
+}
+
+// GetMaplestoryWindowRect finds the MapleStory window and returns its
+// rectangle without activating it
+func GetMaplestoryWindowRect() (*WindowRect, error) {
+	handle, err := GetMaplestoryWindowHandle()
+	if err != nil {
+		return nil, err
+	}
+	return &handle.Rect, nil
+}
 
-	return &rect, nil
+// GetMaplestoryWindow is the old name of GetMaplestoryWindowRect. It no
+// longer activates the window.
+func GetMaplestoryWindow() (*WindowRect, error) {
+	return GetMaplestoryWindowRect()
+}
+
+// now and sleep time the waits in WaitForMaplestory and WaitForForeground
+var (
+	now   = time.Now
+	sleep = time.Sleep
+)
+
+// SetClock replaces the time source of the waits, so they follow the
+// caller's clock (nil = the time package)
+func SetClock(nowFunc func() time.Time, sleepFunc func(time.Duration)) {
+	if nowFunc == nil {
+		nowFunc = time.Now
+	}
+	if sleepFunc == nil {
+		sleepFunc = time.Sleep
+	}
+	now, sleep = nowFunc, sleepFunc
+}
+
+// WaitForMaplestory polls for the MapleStory window every pollInterval until
+// it appears or timeout elapses, then returns its rectangle. Once the window
+// exists it returns whatever GetMaplestoryWindowRect does, so a minimized
+// window still reports ErrMinimized.
+func WaitForMaplestory(timeout time.Duration, pollInterval time.Duration) (*WindowRect, error) {
+	handle, err := WaitForMaplestoryHandle(timeout, pollInterval, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &handle.Rect, nil
+}
+
+// WaitForMaplestoryHandle is WaitForMaplestory returning the handle along
+// with the rectangle, like GetMaplestoryWindowHandle. cancelled is checked
+// between polls; once it returns true the wait ends with ErrCancelled. It may
+// be nil.
+func WaitForMaplestoryHandle(timeout time.Duration, pollInterval time.Duration, cancelled func() bool) (*WindowHandle, error) {
+	deadline := now().Add(timeout)
+	for {
+		_, err := findTargetWindow()
+		if err == nil {
+			return GetMaplestoryWindowHandle()
+		}
+		if cancelled != nil && cancelled() {
+			return nil, ErrCancelled
+		}
+		if !now().Before(deadline) {
+			return nil, fmt.Errorf("gave up after %s: %v", timeout, err)
+		}
+		sleep(pollInterval)
+	}
 }
 
 // FindAndActivateMaplestory finds and activates the MapleStory window
 func FindAndActivateMaplestory() (uintptr, error) {
-	hwnd, _, _ := procFindWindow.Call(
-		0,
-		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("MapleStory"))),
-	)
-
-	if hwnd == 0 {
-		return 0, fmt.Errorf("MapleStory window not found")
+	hwnd, err := findTargetWindow()
+	if err != nil {
+		return 0, err
 	}
 
 	// Set as foreground window
-	ret, _, _ := procSetForegroundWindow.Call(hwnd)
-	if ret == 0 {
-		return 0, fmt.Errorf("failed to activate MapleStory window")
+	if err := (&WindowHandle{HWND: hwnd}).Activate(); err != nil {
+		return 0, err
 	}
 
 	return hwnd, nil
 }
+
+// WaitForForeground waits up to timeout for hwnd to become the foreground
+// window, and reports whether it did
+func WaitForForeground(hwnd uintptr, timeout time.Duration) bool {
+	deadline := now().Add(timeout)
+	for {
+		foreground, _, _ := procGetForegroundWindow.Call()
+		if foreground == hwnd {
+			return true
+		}
+		if !now().Before(deadline) {
+			return false
+		}
+		sleep(focusPollInterval)
+	}
+}
+
+// RestoreIfMinimized restores the MapleStory window if it is minimized, so
+// it can be captured without taking focus. It reports whether a restore was
+// needed.
+func RestoreIfMinimized() (bool, error) {
+	hwnd, err := findTargetWindow()
+	if err != nil {
+		return false, err
+	}
+	return (&WindowHandle{HWND: hwnd}).RestoreIfMinimized()
+}
+
+// IsMinimized reports whether a window is minimized
+func IsMinimized(hwnd uintptr) bool {
+	iconic, _, _ := procIsIconic.Call(hwnd)
+	return iconic != 0
+}
+
+// RestoreWindow restores a minimized window and activates it
+func RestoreWindow(hwnd uintptr) error {
+	procShowWindow.Call(hwnd, SW_RESTORE)
+	if IsMinimized(hwnd) {
+		return fmt.Errorf("failed to restore minimized %s window", targetTitle)
+	}
+	return nil
+}
+
+// AbsoluteClickPos converts an offset relative to the window's top-left corner
+// (or the screen center or client area, with AnchorScreenCenter or
+// AnchorClient) into absolute screen coordinates, rejecting positions outside
+// the window or screen
+func AbsoluteClickPos(rect *WindowRect, offsetX, offsetY int) (x, y int, err error) {
+	if anchor == AnchorScreenCenter {
+		screenWidth, screenHeight := screenSize()
+		originX, originY := anchorOrigin(anchor, rect, screenWidth, screenHeight)
+		x, y = originX+offsetX, originY+offsetY
+		if x < 0 || y < 0 || x >= screenWidth || y >= screenHeight {
+			return 0, 0, fmt.Errorf("click offset (%d,%d) from the screen center is outside the %dx%d screen", offsetX, offsetY, screenWidth, screenHeight)
+		}
+		return x, y, nil
+	}
+
+	if anchor == AnchorClient {
+		rect = clientArea(rect)
+	}
+
+	width := int(rect.Right - rect.Left)
+	height := int(rect.Bottom - rect.Top)
+
+	if offsetX < 0 || offsetY < 0 || offsetX >= width || offsetY >= height {
+		return 0, 0, fmt.Errorf("click offset (%d,%d) is outside the %dx%d window", offsetX, offsetY, width, height)
+	}
+
+	return int(rect.Left) + offsetX, int(rect.Top) + offsetY, nil
+}
@@ -0,0 +1,114 @@
+package window
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestAbsoluteClickPos(t *testing.T) {
+	rect := &WindowRect{Left: 100, Top: 50, Right: 900, Bottom: 650} // 800x600
+
+	tests := []struct {
+		name             string
+		offsetX, offsetY int
+		wantX, wantY     int
+		wantErr          bool
+	}{
+		{"top-left corner", 0, 0, 100, 50, false},
+		{"inside", 350, 420, 450, 470, false},
+		{"last pixel", 799, 599, 899, 649, false},
+		{"right edge", 800, 10, 0, 0, true},
+		{"bottom edge", 10, 600, 0, 0, true},
+		{"negative x", -1, 10, 0, 0, true},
+		{"negative y", 10, -5, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, err := AbsoluteClickPos(rect, tt.offsetX, tt.offsetY)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AbsoluteClickPos(%d, %d) error = %v, wantErr %v", tt.offsetX, tt.offsetY, err, tt.wantErr)
+			}
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("AbsoluteClickPos(%d, %d) = (%d, %d), want (%d, %d)", tt.offsetX, tt.offsetY, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestTargetTitleUnicode(t *testing.T) {
+	defer SetTargetTitle(TargetTitle())
+
+	const title = "메이플스토리 - 楓之谷"
+	SetTargetTitle(title)
+	if got := TargetTitle(); got != title {
+		t.Fatalf("TargetTitle() = %q, want %q", got, title)
+	}
+
+	// The title must survive the UTF-16 round trip FindWindowW and
+	// GetWindowTextW go through
+	encoded, err := syscall.UTF16FromString(title)
+	if err != nil {
+		t.Fatalf("UTF16FromString(%q) error = %v", title, err)
+	}
+	if got := syscall.UTF16ToString(encoded); got != title {
+		t.Errorf("UTF-16 round trip = %q, want %q", got, title)
+	}
+
+	SetTargetTitle("")
+	if got := TargetTitle(); got != DefaultTitle {
+		t.Errorf("empty title gave %q, want %q", got, DefaultTitle)
+	}
+
+	// An embedded NUL is reported instead of panicking
+	SetTargetTitle("Maple\x00Story")
+	if _, err := findTargetWindow(); err == nil {
+		t.Error("findTargetWindow() with an embedded NUL returned no error")
+	}
+}
+
+func TestAnchorOrigin(t *testing.T) {
+	rect := &WindowRect{Left: 320, Top: 180, Right: 1600, Bottom: 900}
+
+	tests := []struct {
+		name          string
+		anchor        Anchor
+		width, height int
+		wantX, wantY  int
+	}{
+		{"window at 1080p", AnchorWindow, 1920, 1080, 320, 180},
+		{"window at 1440p", AnchorWindow, 2560, 1440, 320, 180},
+		{"screen center at 1080p", AnchorScreenCenter, 1920, 1080, 960, 540},
+		{"screen center at 1440p", AnchorScreenCenter, 2560, 1440, 1280, 720},
+		{"screen center at an odd size", AnchorScreenCenter, 1366, 767, 683, 383},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := anchorOrigin(tt.anchor, rect, tt.width, tt.height)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("anchorOrigin(%v) at %dx%d = (%d, %d), want (%d, %d)", tt.anchor, tt.width, tt.height, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+
+	// The window anchor doesn't need the screen size
+	if x, y := Origin(rect); x != 320 || y != 180 {
+		t.Errorf("Origin() with the window anchor = (%d, %d), want (320, 180)", x, y)
+	}
+}
+
+func TestParseAnchor(t *testing.T) {
+	for _, a := range []Anchor{AnchorWindow, AnchorScreenCenter, AnchorClient} {
+		got, err := ParseAnchor(a.String())
+		if err != nil || got != a {
+			t.Errorf("ParseAnchor(%q) = %v, %v, want %v", a.String(), got, err, a)
+		}
+	}
+	if got, err := ParseAnchor(""); err != nil || got != AnchorWindow {
+		t.Errorf("ParseAnchor(\"\") = %v, %v, want the window anchor", got, err)
+	}
+	if _, err := ParseAnchor("center"); err == nil {
+		t.Error("ParseAnchor(\"center\") returned no error")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"maple_flame/internal/screenshot"
+)
+
+// invertMode selects the text polarity fed to OCR
+type invertMode int
+
+const (
+	invertOff  invertMode = iota // OCR the capture as is
+	invertOn                     // Always OCR the inverted capture
+	invertAuto                   // Retry inverted when the normal read isn't flame stats
+)
+
+// String returns the --invert value for the mode
+func (m invertMode) String() string {
+	switch m {
+	case invertOn:
+		return "on"
+	case invertAuto:
+		return "auto"
+	default:
+		return "off"
+	}
+}
+
+// parseInvertMode parses "auto", "on" or "off"
+func parseInvertMode(s string) (invertMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off":
+		return invertOff, nil
+	case "on":
+		return invertOn, nil
+	case "auto":
+		return invertAuto, nil
+	default:
+		return invertOff, fmt.Errorf("invalid --invert value %q (valid options: auto, on, off)", s)
+	}
+}
+
+// statLineCount counts the lines of text that look like flame stats, as a
+// measure of how well a read went
+func statLineCount(text string) int {
+	return countLinesAtLeast(text, validateFlameText, 0)
+}
+
+// pickPolarity keeps the inverted read only if it found more stat lines than
+// the normal one, and reports whether it did
+func pickPolarity(normal, inverted string) (string, bool) {
+	if statLineCount(inverted) > statLineCount(normal) {
+		return inverted, true
+	}
+	return normal, false
+}
+
+// readInverted OCRs the inverted capture the same way the loop reads the
+// normal one
+func readInverted(img *image.RGBA, cfg *Config) (string, error) {
+	filename, err := screenshot.SaveDebugImageWithPrefix(screenshot.InvertImage(img), "inverted", 1)
+	if err != nil {
+		return "", err
+	}
+
+	text, _, err := ocrFile(filename, cfg)
+	return text, err
+}
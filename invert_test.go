@@ -0,0 +1,101 @@
+package main
+
+import (
+	"image"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"maple_flame/internal/screenshot"
+)
+
+func TestParseInvertMode(t *testing.T) {
+	for _, mode := range []invertMode{invertOff, invertOn, invertAuto} {
+		if got, err := parseInvertMode(mode.String()); err != nil || got != mode {
+			t.Errorf("parseInvertMode(%q) = %v, %v, want %v", mode.String(), got, err, mode)
+		}
+	}
+	if got, err := parseInvertMode(" AUTO "); err != nil || got != invertAuto {
+		t.Errorf("parseInvertMode(\" AUTO \") = %v, %v, want auto", got, err)
+	}
+	if _, err := parseInvertMode("yes"); err == nil {
+		t.Error("parseInvertMode(\"yes\") returned no error")
+	}
+}
+
+func TestPickPolarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		normal   string
+		inverted string
+		want     bool // the inverted read is used
+	}{
+		{"inverted reads stats", "~~ ..\n", "STR +12%\nDEX +9%\n", true},
+		{"inverted reads more lines", "STR +12%\n~~\n", "STR +12%\nDEX +9%\n", true},
+		{"same number of lines", "STR +12%\n", "DEX +9%\n", false},
+		{"inverted reads fewer", "STR +12%\nDEX +9%\n", "STR +12%\n", false},
+		{"neither reads stats", "~~\n", "..\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, used := pickPolarity(tt.normal, tt.inverted)
+			want := tt.normal
+			if tt.want {
+				want = tt.inverted
+			}
+			if used != tt.want || text != want {
+				t.Errorf("pickPolarity() = %q, %v, want %q, %v", text, used, want, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStatsInvertRetry(t *testing.T) {
+	const stats = "STR +12%\nDEX +9%\n"
+	const garbage = "~~ ..\n"
+
+	tests := []struct {
+		name         string
+		mode         invertMode
+		normal       string
+		inverted     string
+		want         string
+		wantInverted bool // the inverted capture was read
+	}{
+		{"good read is kept", invertAuto, stats, garbage, stats, false},
+		{"poor read retried inverted", invertAuto, garbage, stats, stats, true},
+		{"inverted no better", invertAuto, garbage, "..\n", garbage, true},
+		{"off never retries", invertOff, garbage, stats, garbage, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer screenshot.SetOutputDir(screenshot.OutputDir())
+			screenshot.SetOutputDir(t.TempDir())
+
+			readInverted := false
+			cfg := &Config{
+				Invert: tt.mode,
+				OCR: func(path string) (string, error) {
+					if strings.HasPrefix(filepath.Base(path), "inverted") {
+						readInverted = true
+						return tt.inverted, nil
+					}
+					return tt.normal, nil
+				},
+			}
+
+			read, err := readStats(image.NewRGBA(image.Rect(0, 0, 8, 4)), nil, cfg, "test", 1)
+			if err != nil {
+				t.Fatalf("readStats() error = %v", err)
+			}
+			if read.Text != tt.want {
+				t.Errorf("read %q, want %q", read.Text, tt.want)
+			}
+			if readInverted != tt.wantInverted {
+				t.Errorf("inverted capture read = %v, want %v", readInverted, tt.wantInverted)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// resolveItemName reads the item name from the configured region, falling
+// back to the literal name when there is no region or nothing was read
+func resolveItemName(handle *window.WindowHandle, cfg *Config) string {
+	if cfg.ItemNameRegion.IsZero() {
+		return cfg.ItemName
+	}
+
+	img, err := screenshot.CaptureHandleRegion(handle, cfg.ItemNameRegion)
+	if err != nil {
+		fmt.Printf("⚠️ Item name capture failed: %v\n", err)
+		return cfg.ItemName
+	}
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "item_name", 1)
+	if err != nil {
+		fmt.Printf("⚠️ Item name save failed: %v\n", err)
+		return cfg.ItemName
+	}
+
+	text, err := ocr.ExtractText(filename)
+	if err != nil {
+		fmt.Printf("⚠️ Item name OCR failed: %v\n", err)
+		return cfg.ItemName
+	}
+
+	if name := firstTextLine(text); name != "" {
+		return name
+	}
+	return cfg.ItemName
+}
+
+// firstTextLine returns the first non-empty line of OCR text
+func firstTextLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+const (
+	historySize   = 20 // Number of recent attempts kept for stuck/plateau checks
+	stuckAttempts = 3  // Identical OCR results in a row before declaring stuck
+	maxBadReads   = 5  // Consecutive non-flame OCR results before aborting
+
+	uniformTolerance = 8                      // Per-channel spread still treated as one color
+	blankRetryDelay  = 200 * time.Millisecond // Wait between re-captures of a uniform frame
+
+	textInkThreshold = 128  // Brightness above which a pixel counts as text for TextBand
+	textBandDensity  = 0.01 // Fraction of a row that must be ink for it to be part of the text band
+	textBandMargin   = 4    // Rows kept above and below the text band
+)
+
+// Config holds the options shared by the armor and weapon reroll loops
+type Config struct {
+	Plateau          int                // Stop after this many attempts without a new best score (0 = off)
+	UnchangedEpsilon int                // Score gain needed to count as a new best for Plateau
+	CropMargins      screenshot.Margins // Pixels trimmed from the capture before OCR
+	TrendWindow      int                // Rerolled attempts averaged by the negative trend guard (0 = off)
+	TrendRatio       float64            // Abort when that average drops below this fraction of the first score
+	MilestoneEvery   int                // Save a permanent screenshot every N attempts (0 = off)
+	StatAliases      ocr.StatAliases    // OCR text fixes applied before scoring
+	SaveEnhanced     bool               // OCR through the enhancement pipeline and keep the enhanced image
+	Clock            Clock              // Time source for sleeps and timestamps, shared with the internal packages by shareClock (nil = real time)
+	ParkCursor       bool               // Move the cursor out of the way before each capture
+	ParkX, ParkY     int                // Cursor park position relative to the window
+	WatchdogTimeout  time.Duration      // Recover when no attempt completes within this time (0 = off)
+	WatchdogAbort    bool               // Abort instead of re-activating the window on a stall
+	ConfirmRegion    screenshot.Region  // Where the reroll confirmation dialog appears (zero = blind double Enter)
+	ConfirmTimeout   time.Duration      // How long to wait for the confirmation dialog
+	Stream           *resultStream      // Live JSON-lines output of each attempt (nil = off)
+	UICheck          *uiPixelCheck      // Pixel that confirms the reroll UI is open (nil = off)
+	AdaptiveDelay    bool               // Tune the post-reroll wait from the observed UI update time
+	DelayMin         time.Duration      // Lower bound for the adaptive delay
+	DelayMax         time.Duration      // Upper bound for the adaptive delay
+	BlankRetries     int                // Re-captures while the region is a single uniform color
+	ItemName         string             // Label for the item being rerolled
+	ItemNameRegion   screenshot.Region  // Where to read the item name from (zero = use ItemName)
+	MinStatSum       int                // Armor: stop when the stat sum reaches this instead of counting lines (0 = off)
+	Class            *classProfile      // Armor: weights for the stat sum instead of main stat + All Stats (nil = off)
+	AllStatWeight    float64            // Armor: main stat worth of 1% All Stats in the stat sum
+	StatCaps         statCaps           // Stop once any of these stats reaches its cap (nil = off)
+	StopExpr         *stopExpr          // Custom stop rule over stat values, replacing the mode's rule and TargetScore (nil = off)
+	TargetScore      int                // Stop once the score reaches this value, replacing the mode's own rule (0 = off)
+	RequiredLines    int                // Matching stat lines needed for the line-count rule (--max-lines)
+	MinLineValue     int                // Only count lines with at least this value (0 = any)
+	VerifyFrames     int                // Extra frames that must agree before stopping on a success (0 = off)
+	KeyHold          Delay              // How long reroll key presses are held down
+	ActivateDelay    time.Duration      // Settle time after the window takes focus, before clicking
+	RegionScan       bool               // Search the whole window for the stat box after repeated bad reads
+	FlameBudget      int                // Stop after this many rerolls that changed the stats (0 = off)
+	DebugClick       bool               // Save a capture around the reroll click each attempt
+	TextBand         bool               // Crop each capture to the rows that contain text before OCR
+	ConfirmX         int                // Confirm button offset clicked after the reroll click (-1 = press Enter)
+	ConfirmY         int
+	Frames           frameSource         // Where captures come from instead of the game window (nil = live)
+	RebaselineKey    *automation.Hotkey  // Hotkey that resets history, plateau and trend state (nil = off)
+	StableFrames     int                 // Consecutive matching captures that end the post-reroll wait (0 = fixed delay)
+	StableTimeout    time.Duration       // Longest wait for the frames to stop changing
+	ExactMain        int                 // Armor: stop only on a main stat line of exactly this value (0 = off)
+	StuckGrace       int                 // Attempts at the start of a session ignored by stuck detection
+	Invert           invertMode          // Text polarity fed to OCR: off, on, or auto-retry inverted
+	Montage          int                 // Captures stacked into temp/montage.png at session end (0 = off)
+	MinConfidence    float64             // Mean OCR word confidence (0-100) a read needs to be acted on (0 = off)
+	MaxScore         int                 // Best score the item can roll, for the 0-100 roll quality (0 = off)
+	PanicOnUnknown   bool                // Abort before clicking if unexpected text (an unknown dialog) appears
+	PanicRegion      screenshot.Region   // Area checked for unexpected text, required by PanicOnUnknown
+	PanicAllow       map[string]bool     // Words that count as part of the reroll flow (nil unless PanicOnUnknown)
+	TallPanel        bool                // Also read the region below the capture and merge the lines
+	Sounds           soundSet            // Sound played for each outcome (nil = silent)
+	RerollDelay      Delay               // Wait after a reroll before the next capture, without adaptive or stable-frame waits
+	ClickSettle      Delay               // Wait after the reroll click before confirming it
+	TakeoverKey      *automation.HoldKey // While held, the loop stops capturing and clicking (nil = off)
+	WaitForWindow    time.Duration       // How long to wait for the game window at startup (0 = don't wait)
+	Background       bool                // Post reroll input to the window instead of activating it and moving the cursor
+	OCR              ocrFunc             // Replaces tesseract, e.g. with canned text in tests (nil = tesseract)
+	OCRConfidence    ocrConfidenceFunc   // Replaces the tesseract read --min-confidence uses, like OCR (nil = tesseract)
+	AutoDPIScale     bool                // Read the click scale from the window's DPI once it is found (--dpi-scale=auto)
+	Stop             *stopRequest        // Ctrl+C and watchdog stop requests, shared with main (nil = the loop watches Ctrl+C itself)
+	AutoRestore      bool                // Restore the window before capture if it is minimized
+}
+
+// HasConfirmClick reports whether a confirm button click replaces pressing Enter
+func (c *Config) HasConfirmClick() bool {
+	return c.ConfirmX >= 0 && c.ConfirmY >= 0
+}
+
+// rerollMode describes how a reroll loop scores OCR text and when it succeeds
+type rerollMode struct {
+	// Name identifies the mode in reported results ("armor" or "weapon")
+	Name string
+	// Evaluate scores the OCR text and reports whether the stats are good enough
+	Evaluate func(text string) (score int, success bool)
+	// Describe returns the progress line printed after each OCR
+	Describe func(score int) string
+	// SuccessMessage returns the line printed when the loop stops on success
+	SuccessMessage func(score int) string
+	// Qualifies optionally adds a requirement on top of the score (nil = none)
+	Qualifies func(text string) (ok bool, reason string)
+	// RetryMessage is printed before clicking reroll
+	RetryMessage string
+	// ConfigHint points the user at the flags that select the target stats
+	ConfigHint string
+}
+
+// runRerollLoop captures, reads and rerolls until the mode succeeds or a stop
+// condition triggers. handle is the game window found at startup, reused for
+// every attempt; it is nil in demo mode.
+func runRerollLoop(handle *window.WindowHandle, mode rerollMode, cfg *Config) {
+	windowRect := handle.Bounds()
+	clock := cfg.clock()
+	attemptCount := 0
+	bestScore := -1
+	badReads := 0
+	history := newAttemptHistory(historySize)
+	decider := newDecider(mode, cfg)
+	budget := &flameBudget{limit: cfg.FlameBudget}
+	var lastFrame *image.RGBA // Capture of the last recorded attempt, for the budget
+	var montage []screenshot.MontageFrame
+	timings := &metricsTotals{}
+	plateau := &plateauDetector{limit: cfg.Plateau, epsilon: cfg.UnchangedEpsilon}
+	trend := &trendGuard{window: cfg.TrendWindow, ratio: cfg.TrendRatio}
+	var delay *adaptiveDelay
+	if cfg.AdaptiveDelay {
+		delay = newAdaptiveDelay(cfg.DelayMin, cfg.DelayMax)
+	}
+	capture := func() (*image.RGBA, error) {
+		if cfg.Frames != nil {
+			return cfg.Frames.Capture()
+		}
+		return screenshot.CaptureHandleRegion(handle, statRegion(windowRect))
+	}
+	// Treat Ctrl+C like the stop key; a second Ctrl+C force quits. main
+	// installs the handler before the window wait; without it the loop
+	// installs its own.
+	stop := cfg.Stop
+	if stop == nil {
+		stop = &stopRequest{}
+		defer watchInterrupts(stop)()
+	}
+
+	// captureCropped captures frames comparable with the cropped capture the
+	// loop keeps as before
+	captureCropped := func() (*image.RGBA, error) {
+		img, err := capture()
+		if err != nil {
+			return nil, err
+		}
+		return screenshot.Crop(img, cfg.CropMargins), nil
+	}
+
+	dog, stopWatchdog := startWatchdog(cfg, stop, handle)
+	beat := func() {
+		if dog != nil {
+			dog.Beat()
+		}
+	}
+	defer stopWatchdog()
+
+	// stopRequested is checked between the phases of an attempt, so a stop
+	// doesn't wait for a whole capture, OCR and reroll cycle
+	stopRequested := func() bool {
+		reason, ok := stop.Requested()
+		if ok {
+			fmt.Printf("\n🛑 %s - stopping gracefully...\n", reason)
+		}
+		return ok
+	}
+
+	// Clean up and summarize however the loop ends: release the modifier
+	// keys first, so the keyboard is the user's again even if the summary
+	// fails. main flushes the log once the loop has returned.
+	defer func() {
+		releaseModifiers()
+		fmt.Printf("\n📊 Session summary: %d attempts, %d flames used", attemptCount, budget.used)
+		if bestScore >= 0 {
+			fmt.Printf(", best score %d", bestScore)
+			if cfg.MaxScore > 0 {
+				fmt.Printf(" (%d/100)", normalizeScore(bestScore, cfg.MaxScore))
+			}
+		}
+		fmt.Println()
+		if avg := timings.String(); avg != "" {
+			fmt.Printf("⏱️ Average per attempt: %s\n", avg)
+		}
+		if len(montage) > 0 {
+			if filename, err := screenshot.SaveMontage(montage); err != nil {
+				fmt.Printf("⚠️ Montage failed: %v\n", err)
+			} else {
+				fmt.Printf("🖼️ Montage of the last %d attempts saved: %s\n", len(montage), filename)
+			}
+		}
+	}()
+
+	itemName := resolveItemName(handle, cfg)
+	if itemName != "" {
+		fmt.Printf("🏷️ Item: %s\n", itemName)
+	}
+
+	if cfg.RebaselineKey != nil {
+		fmt.Printf("🔄 Press %s to re-baseline after rerolling by hand\n", cfg.RebaselineKey.Name())
+	}
+	if cfg.TakeoverKey != nil {
+		fmt.Printf("✋ Hold %s to pause and take over by hand\n", cfg.TakeoverKey.Name())
+	}
+
+	if cfg.StopExpr != nil {
+		fmt.Printf("🎯 Stop expression: %s (replaces the mode's stop rule)\n", cfg.StopExpr)
+	} else if cfg.TargetScore > 0 {
+		fmt.Printf("🎯 Target score: %d (replaces the mode's own stop rule)\n", cfg.TargetScore)
+	}
+
+	for {
+		if dog != nil {
+			dog.Beat()
+		}
+
+		attemptCount++
+		if itemName != "" {
+			fmt.Printf("=== Attempt #%d [%s] ===\n", attemptCount, itemName)
+		} else {
+			fmt.Printf("=== Attempt #%d ===\n", attemptCount)
+		}
+
+		// Check for the stop key or Ctrl+C to stop gracefully
+		if automation.CheckStopKey() {
+			fmt.Println("\n🛑 Stop key pressed - stopping gracefully...")
+			break
+		}
+		// Let the user throw away the comparison state after rerolling by hand
+		if cfg.RebaselineKey != nil && cfg.RebaselineKey.Pressed() {
+			history = newAttemptHistory(historySize)
+			lastFrame = nil
+			plateau = &plateauDetector{limit: cfg.Plateau, epsilon: cfg.UnchangedEpsilon}
+			trend = &trendGuard{window: cfg.TrendWindow, ratio: cfg.TrendRatio}
+			badReads = 0
+			fmt.Println("🔄 Re-baseline requested - starting the comparison over from this attempt")
+		}
+		if waitWhileHeld(cfg.TakeoverKey, clock, beat, stop) {
+			fmt.Println("\n🛑 Stopping gracefully...")
+			break
+		}
+		if stopRequested() {
+			break
+		}
+
+		// Cheap check that the reroll UI is still open before capture and OCR
+		if cfg.UICheck != nil {
+			open, sample, err := uiIsOpen(handle, *cfg.UICheck)
+			if err != nil {
+				fmt.Printf("⚠️ UI check failed: %v\n", err)
+			} else if !open {
+				fmt.Printf("⏸️ Reroll UI not detected (pixel is #%02X%02X%02X) - waiting...\n", sample.R, sample.G, sample.B)
+				clock.Sleep(1 * time.Second)
+				continue
+			}
+		}
+
+		// The handle found at startup is used throughout; once the game is
+		// closed there is nothing left to reroll
+		if handle != nil && !handle.Valid() {
+			fmt.Println("\n🛑 MapleStory window was closed - stopping script...")
+			cfg.Sounds.Play(outcomeError)
+			break
+		}
+
+		// A minimized window captures as garbage; bring it back first
+		if handle != nil && cfg.AutoRestore {
+			restored, err := handle.RestoreIfMinimized()
+			if err != nil {
+				fmt.Printf("⚠️ %v\n", err)
+			} else if restored {
+				fmt.Println("🪟 Window was minimized - restored it")
+				clock.Sleep(blankRetryDelay)
+			}
+		} else if handle != nil && handle.Minimized() {
+			// Don't read the black capture of a minimized window as stuck stats
+			fmt.Printf("⏸️ %s %v - waiting...\n", window.TargetTitle(), window.ErrMinimized)
+			clock.Sleep(1 * time.Second)
+			continue
+		}
+
+		// Keep the cursor sprite out of the capture
+		if cfg.ParkCursor {
+			if err := automation.ParkCursor(windowRect, cfg.ParkX, cfg.ParkY); err != nil {
+				fmt.Printf("⚠️ %v\n", err)
+			}
+		}
+
+		// Capture screenshot
+		var metrics Metrics
+		phaseStart := clock.Now()
+		fmt.Print("Capturing... ")
+		img, err := capture()
+		if err != nil {
+			fmt.Printf("❌ Screenshot failed: %v\n", err)
+			continue
+		}
+
+		automation.LogAction(automation.Action{Type: "capture", Attempt: attemptCount})
+
+		// Trim decorative panel borders before OCR
+		img = screenshot.Crop(img, cfg.CropMargins)
+
+		// A single-color frame is a UI transition; give it a moment to finish
+		for retry := 1; retry <= cfg.BlankRetries && screenshot.IsUniformColor(img, uniformTolerance); retry++ {
+			fmt.Printf("⚠️ Capture is a single color, retrying (%d/%d)... ", retry, cfg.BlankRetries)
+			clock.Sleep(blankRetryDelay)
+			retryImg, err := capture()
+			if err != nil {
+				fmt.Printf("❌ Screenshot failed: %v\n", err)
+				break
+			}
+			img = screenshot.Crop(retryImg, cfg.CropMargins)
+		}
+
+		// measureUpdateTime compares later captures against this one, so keep
+		// it before --text-band and --invert change its size and colors
+		before := img
+		metrics.CaptureMs = elapsedMs(phaseStart, clock.Now())
+		fmt.Println("✅")
+		if stopRequested() {
+			break
+		}
+
+		// Save for debugging (max 1 screenshot, always overwrites) and apply
+		// OCR, with every read option, text band through tall panel
+		phaseStart = clock.Now()
+		fmt.Print("OCR... ")
+		read, err := readStats(img, handle, cfg, "", 1)
+		if err != nil {
+			fmt.Printf("❌ OCR failed: %v\n", err)
+			clock.Sleep(1 * time.Second)
+			continue
+		}
+		fmt.Printf("✅ Done (%s)\n", read.Filename)
+		img = read.Image
+		text := read.Text
+		metrics.Confidence = read.Confidence
+
+		// Keep the last --montage captures for the end-of-session montage
+		if cfg.Montage > 0 {
+			montage = append(montage, screenshot.MontageFrame{Attempt: attemptCount, Image: img})
+			if len(montage) > cfg.Montage {
+				montage = montage[1:]
+			}
+		}
+
+		// An unsure read shouldn't decide anything; capture again instead,
+		// counting it as a bad read so a panel that never reads clearly
+		// stops the session
+		if !confidentRead(metrics.Confidence, cfg.MinConfidence) {
+			badReads++
+			fmt.Printf("⚠️ OCR confidence %.0f is below %.0f (%d/%d), capturing again\n", metrics.Confidence, cfg.MinConfidence, badReads, maxBadReads)
+			if badReads >= maxBadReads {
+				fmt.Println("\n🛑 OCR stays unsure of the stats - check the capture region or lower --min-confidence.")
+				fmt.Println("Stopping script...")
+				cfg.Sounds.Play(outcomeError)
+				break
+			}
+			clock.Sleep(blankRetryDelay)
+			continue
+		}
+		metrics.OCRMs = elapsedMs(phaseStart, clock.Now())
+		if stopRequested() {
+			break
+		}
+
+		// Make sure we are actually reading the flame stat box
+		if err := checkFlameText(text); err != nil {
+			badReads++
+			fmt.Printf("⚠️ Not flame stats (%d/%d): %v\n", badReads, maxBadReads, err)
+			if badReads >= maxBadReads {
+				fmt.Println("\n🛑 Capture region doesn't seem to contain the flame stats - check the capture offsets.")
+				if cfg.RegionScan {
+					if region, found := scanForStatRegion(handle, cfg); found {
+						fmt.Printf("💡 Flame stats found at %s - set CAPTURE_X=%d and CAPTURE_Y=%d\n", region, region.X, region.Y)
+					} else {
+						fmt.Println("No region of the window looks like flame stats - is the reroll UI open?")
+					}
+				}
+				fmt.Println("Stopping script...")
+				cfg.Sounds.Play(outcomeError)
+				break
+			}
+			clock.Sleep(1 * time.Second)
+			continue
+		}
+		badReads = 0
+
+		phaseStart = clock.Now()
+		score, _ := mode.Evaluate(text)
+
+		if score > bestScore {
+			if bestScore >= 0 {
+				cfg.Sounds.Play(outcomeBest)
+			}
+			bestScore = score
+		}
+
+		success, reason := decider.ShouldStop(text, score, history.Recent(history.Len()))
+		if reason != "" {
+			fmt.Println(reason)
+		}
+
+		// Guard the stop decision against a single hallucinated read
+		if success && cfg.VerifyFrames > 0 {
+			fmt.Printf("🔍 Confirming success with %d more frame(s)...\n", cfg.VerifyFrames)
+			earlier := history.Recent(history.Len())
+			confirmed, why := confirmSuccess(cfg.VerifyFrames,
+				func(frame int) (string, error) { return readVerifyFrame(handle, cfg, frame) },
+				func(text string) bool {
+					frameScore, _ := mode.Evaluate(text)
+					stop, _ := decider.ShouldStop(text, frameScore, earlier)
+					return stop
+				},
+				clock.Sleep)
+			if !confirmed {
+				fmt.Printf("⚠️ Success not confirmed (%s) - treating it as a misread\n", why)
+				success = false
+			}
+		}
+
+		metrics.DecideMs = elapsedMs(phaseStart, clock.Now())
+		metrics.Stats = stopExprValues(text, score)
+		timings.Add(metrics)
+
+		// Keep a sparse visual timeline of long sessions
+		if screenshot.IsMilestone(attemptCount, cfg.MilestoneEvery) {
+			if milestone, err := screenshot.SaveMilestoneImage(img, attemptCount, score); err != nil {
+				fmt.Printf("⚠️ Milestone save failed: %v\n", err)
+			} else {
+				fmt.Printf("📸 Milestone saved: %s\n", milestone)
+			}
+		}
+
+		// Store this result in our history for stuck and plateau detection
+		result := attemptResult{
+			Time:    clock.Now(),
+			Mode:    mode.Name,
+			Item:    itemName,
+			Attempt: attemptCount,
+			Score:   score,
+			Success: success,
+			Text:    strings.TrimSpace(text),
+			Metrics: metrics,
+		}
+
+		// A reroll only consumed a flame if the stats actually changed
+		if lastFrame != nil {
+			budget.Observe(lastFrame, before)
+		}
+		lastFrame = before
+		history.Add(result)
+
+		// Check if stats are stuck (same for 3 consecutive attempts), once
+		// the attempts compared all fall after the --stuck-grace period.
+		// Only recorded results count: skipped attempts (bad reads, UI
+		// closed) are not part of the grace period.
+		if history.Recorded()-stuckAttempts >= cfg.StuckGrace && history.IsStuck(stuckAttempts) {
+			fmt.Printf("\n⚠️ STUCK DETECTED: Stats haven't changed for %d consecutive attempts!\n", stuckAttempts)
+			fmt.Printf("Last OCR result: %s\n", history.Recent(1)[0].Text)
+			fmt.Println("🛑 Reroll mechanism may not be working - stopping script...")
+			cfg.Sounds.Play(outcomeStuck)
+			break
+		}
+
+		fmt.Printf("Text extracted:\n%s\n", text)
+		fmt.Println(mode.Describe(score))
+		if cfg.MaxScore > 0 {
+			fmt.Printf("📏 Roll quality: %d/100 (score %d of %d)\n", normalizeScore(score, cfg.MaxScore), score, cfg.MaxScore)
+		}
+		fmt.Printf("⏱️ %s\n", metrics)
+
+		automation.LogAction(automation.Action{
+			Type:    "decision",
+			Attempt: attemptCount,
+			Detail:  fmt.Sprintf("score=%d success=%t", score, success),
+		})
+
+		if err := cfg.Stream.Write(result); err != nil {
+			fmt.Printf("⚠️ Stream write failed: %v\n", err)
+		}
+
+		// Check if we should stop
+		if success {
+			fmt.Printf("\n🎉 SUCCESS! %s\n", mode.SuccessMessage(score))
+			fmt.Println("Stopping reroll - good stats achieved!")
+			cfg.Sounds.Play(outcomeSuccess)
+			break
+		}
+
+		if budget.Exhausted() {
+			fmt.Printf("\n💸 FLAME BUDGET EXHAUSTED: %d of %d flames used\n", budget.used, budget.limit)
+			fmt.Println("🛑 Stopping reroll...")
+			break
+		}
+
+		// Check if the best score has stopped improving
+		if plateau.Observe(score) {
+			fmt.Printf("\n📉 PLATEAU: no improvement in %d attempts (best score: %d)\n", cfg.Plateau, plateau.best)
+			fmt.Println("🛑 Stopping reroll...")
+			break
+		}
+
+		// Check if rerolls keep landing far below the starting stats
+		if mean, low := trend.Check(history, score); low {
+			fmt.Printf("\n⚠️ NEGATIVE TREND: average score over the last %d attempts (%.2f) is below %.0f%% of the starting score (%d)\n",
+				trend.window, mean, trend.ratio*100, trend.baseline)
+			fmt.Printf("🛑 The target stats may be misconfigured - %s\n", mode.ConfigHint)
+			cfg.Sounds.Play(outcomeError)
+			break
+		}
+
+		// Not good enough, click to reroll
+		fmt.Println(mode.RetryMessage)
+		if cfg.Frames != nil {
+			// Demo frames pace themselves
+			cfg.Frames.Reroll()
+			continue
+		}
+
+		// An unexpected popup could swallow or redirect the click; stop first
+		if cfg.PanicOnUnknown {
+			unknown, err := checkForUnknownDialog(handle, cfg)
+			if err != nil {
+				fmt.Printf("\n🚨 PANIC: could not check the screen for unexpected dialogs: %v\n", err)
+				cfg.Sounds.Play(outcomeError)
+				break
+			}
+			if len(unknown) > 0 {
+				fmt.Printf("\n🚨 PANIC: unexpected text on screen: %s\n", strings.Join(unknown, ", "))
+				fmt.Println("🛑 Stopping before clicking - add expected words with --panic-allow if this was a false alarm")
+				cfg.Sounds.Play(outcomeError)
+				break
+			}
+		}
+		// The user may have taken over since the capture
+		if waitWhileHeld(cfg.TakeoverKey, clock, beat, stop) {
+			fmt.Println("\n🛑 Stopping gracefully...")
+			break
+		}
+		if stopRequested() {
+			break
+		}
+		triggerReroll(handle, cfg, attemptCount)
+
+		// Wait a moment before next attempt
+		if cfg.StableFrames > 0 {
+			if _, stable := waitForStableFrame(before, cfg.StableFrames, cfg.StableTimeout, captureCropped, clock); !stable {
+				fmt.Printf("⚠️ Stats not settled after %s, reading them anyway\n", cfg.StableTimeout)
+			}
+		} else if delay == nil {
+			clock.Sleep(cfg.RerollDelay.Duration())
+		} else if delay.Calibrating() {
+			measured := measureUpdateTime(before, captureCropped, clock, delay.max)
+			fmt.Printf("⏱️ UI updated after %s, reroll delay now %s\n", measured.Round(time.Millisecond), delay.Observe(measured).Round(time.Millisecond))
+		} else {
+			clock.Sleep(delay.Delay())
+		}
+	}
+}
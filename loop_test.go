@@ -0,0 +1,319 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"maple_flame/internal/screenshot"
+)
+
+// runScriptedLoop runs the reroll loop on a fake clock, reading texts in turn
+// (the last one repeats) and scoring STR lines, and returns how many reads
+// it made. cfg.Clock and cfg.OCR are filled in, and cfg.Frames too unless it
+// is already set; a *demoFrames is then put on the fake clock.
+func runScriptedLoop(t *testing.T, cfg *Config, texts []string) int {
+	t.Helper()
+	return runScriptedLoopWith(t, cfg, func(n int) string {
+		return texts[min(n, len(texts)-1)]
+	})
+}
+
+// runScriptedLoopWith is runScriptedLoop with read giving the text of the
+// nth read, counting from 0
+func runScriptedLoopWith(t *testing.T, cfg *Config, read func(n int) string) int {
+	t.Helper()
+	defer screenshot.SetOutputDir(screenshot.OutputDir())
+	screenshot.SetOutputDir(t.TempDir())
+
+	clock := newFakeClock()
+	reads := 0
+	cfg.Clock = clock
+	if frames, ok := cfg.Frames.(*demoFrames); ok {
+		frames.clock = clock
+	} else if cfg.Frames == nil {
+		frame := image.NewRGBA(image.Rect(0, 0, 20, 10))
+		cfg.Frames = &demoFrames{dir: "test", frames: []*image.RGBA{frame}, interval: time.Second, clock: clock}
+	}
+	cfg.OCR = func(string) (string, error) {
+		text := read(reads)
+		reads++
+		return text, nil
+	}
+	if cfg.RequiredLines == 0 {
+		cfg.RequiredLines = defaultRequiredLines
+	}
+
+	mode := rerollMode{
+		Name: "armor",
+		Evaluate: func(text string) (int, bool) {
+			count := countMainStatLines(text, STR, 0)
+			return count, enoughLines(count, cfg)
+		},
+		Describe:       func(score int) string { return "" },
+		SuccessMessage: func(score int) string { return "" },
+	}
+	runRerollLoop(nil, mode, cfg)
+	return reads
+}
+
+func TestStuckGrace(t *testing.T) {
+	const same = "DEX +9%\nMax HP +3%\n"
+	const garbage = "~~ .. ~~"
+
+	tests := []struct {
+		name  string
+		grace int
+		texts []string
+		want  int // reads before the loop stops as stuck
+	}{
+		{"no grace", 0, []string{same}, stuckAttempts},
+		{"grace of one", 1, []string{same}, stuckAttempts + 1},
+		{"grace of two", 2, []string{same}, stuckAttempts + 2},
+		// Unreadable captures are skipped, not recorded, so they don't use up the grace
+		{"skipped reads don't count", 2, []string{garbage, garbage, same}, 2 + stuckAttempts + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runScriptedLoop(t, &Config{StuckGrace: tt.grace}, tt.texts); got != tt.want {
+				t.Errorf("loop stopped after %d reads, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoodFirstFrameSkipsReroll(t *testing.T) {
+	const good = "STR +12%\nAll Stats +6%\n"
+	const bad = "DEX +9%\nMax HP +3%\n"
+	bossExpr, err := parseStopExpr("BOSS >= 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *Config
+		texts       []string
+		wantRerolls int
+	}{
+		{"line rule met", &Config{}, []string{good}, 0},
+		{"target score met", &Config{TargetScore: 1}, []string{"STR +12%\nDEX +9%\n"}, 0},
+		{"stop expression met", &Config{StopExpr: bossExpr}, []string{"Boss Damage +30%\nDEX +9%\n"}, 0},
+		{"met after one reroll", &Config{}, []string{bad, good}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runScriptedLoop(t, tt.cfg, tt.texts)
+			if rerolls := tt.cfg.Frames.(*demoFrames).next; rerolls != tt.wantRerolls {
+				t.Errorf("loop rerolled %d times, want %d", rerolls, tt.wantRerolls)
+			}
+		})
+	}
+}
+
+func TestFlameBudgetConsumedVsFailed(t *testing.T) {
+	// Different OCR text every read, none of it a success
+	misreads := []string{"DEX +9%\n", "DEX +8%\n", "DEX +6%\n", "DEX +9%\nMax HP +3%\n", "DEX +3%\n"}
+	good := "STR +12%\nAll Stats +6%\n"
+
+	tests := []struct {
+		name      string
+		frames    []*image.RGBA
+		wantReads int
+	}{
+		// The click never changed the stat box: the text differs only
+		// through misreads, so no flame is used and the budget never runs out
+		{"failed rerolls", []*image.RGBA{solidFrame(100)}, len(misreads) + 1},
+		// Every reroll changed the stat box: the budget of 2 runs out on the 3rd read
+		{"consumed flames", []*image.RGBA{solidFrame(100), solidFrame(200)}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				FlameBudget: 2,
+				Frames:      &demoFrames{dir: "test", frames: tt.frames, interval: time.Second},
+			}
+			reads := runScriptedLoopWith(t, cfg, func(n int) string {
+				if n < len(misreads) {
+					return misreads[n]
+				}
+				return good
+			})
+			if reads != tt.wantReads {
+				t.Errorf("loop stopped after %d reads, want %d", reads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestTrendWindowAbort(t *testing.T) {
+	const baseline = "STR +9%\nSTR +6%\nSTR +3%\n"
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  int // reads before the loop stops
+	}{
+		// Three rerolls averaging 0 against a baseline of 3 abort the run
+		{"low trend", []string{baseline, "DEX +9%\nMax HP +3%\n", "DEX +6%\nMax HP +3%\n", "DEX +3%\nMax HP +3%\n"}, 4},
+		// Averaging 2 stays above half the baseline, so only the stuck check stops it
+		{"trend holds", []string{baseline, "STR +9%\nSTR +6%\nDEX +3%\n", "STR +6%\nSTR +3%\nDEX +9%\n", "STR +9%\nSTR +3%\nDEX +6%\n"}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{RequiredLines: 4, TrendWindow: 3, TrendRatio: 0.5}
+			if got := runScriptedLoop(t, cfg, tt.texts); got != tt.want {
+				t.Errorf("loop stopped after %d reads, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlateauStopsLoop(t *testing.T) {
+	texts := []string{
+		"STR +9%\nSTR +6%\n",
+		"STR +9%\nDEX +6%\n",
+		"DEX +9%\nMax HP +3%\n",
+		"STR +6%\nLUK +3%\n",
+		"STR +3%\nINT +3%\n",
+	}
+	cfg := &Config{RequiredLines: 4, Plateau: 3}
+	if got := runScriptedLoop(t, cfg, texts); got != 4 {
+		t.Errorf("loop stopped after %d reads, want 4", got)
+	}
+}
+
+func TestUnrelatedTextAborts(t *testing.T) {
+	const inventory = "Mesos 1,234,567\nEquip Use Etc Setup Cash\n"
+	if got := runScriptedLoop(t, &Config{}, []string{inventory}); got != maxBadReads {
+		t.Errorf("loop stopped after %d reads, want %d", got, maxBadReads)
+	}
+}
+
+// transitionFrames is a frameSource whose first captures are single-color
+// transition frames
+type transitionFrames struct {
+	uniform  int // Captures that come back as one color
+	captures int
+}
+
+// Capture implements frameSource
+func (f *transitionFrames) Capture() (*image.RGBA, error) {
+	f.captures++
+	if f.captures <= f.uniform {
+		return solidFrame(255), nil
+	}
+	img := solidFrame(0)
+	img.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	return img, nil
+}
+
+// Reroll implements frameSource
+func (f *transitionFrames) Reroll() {}
+
+func TestBlankRetries(t *testing.T) {
+	const good = "STR +12%\nAll Stats +6%\n"
+
+	tests := []struct {
+		name    string
+		retries int
+		uniform int
+		want    int // captures before the first OCR read
+	}{
+		{"no transition", 3, 0, 1},
+		{"transition clears", 3, 2, 3},
+		{"retries run out", 2, 5, 3},
+		{"retries off", 0, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := &transitionFrames{uniform: tt.uniform}
+			captures := 0
+			runScriptedLoopWith(t, &Config{BlankRetries: tt.retries, Frames: frames}, func(n int) string {
+				if n == 0 {
+					captures = frames.captures
+				}
+				return good
+			})
+			if captures != tt.want {
+				t.Errorf("%d captures before OCR, want %d", captures, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinConfidenceGate(t *testing.T) {
+	const good = "STR +12%\nAll Stats +6%\n"
+	const bad = "DEX +9%\n"
+
+	type read struct {
+		text       string
+		confidence float64
+	}
+
+	tests := []struct {
+		name        string
+		reads       []read // the last one repeats
+		wantReads   int
+		wantRerolls int
+	}{
+		{"confident success stops", []read{{good, 90}}, 1, 0},
+		// Without the gate the first read would already stop the session
+		{"unsure success is read again", []read{{good, 40}, {good, 90}}, 2, 0},
+		// The unsure read doesn't reroll; only the confident miss does
+		{"unsure miss doesn't reroll", []read{{bad, 40}, {bad + "LUK +3%\n", 90}, {good, 90}}, 3, 1},
+		{"never confident", []read{{good, 40}}, maxBadReads, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := &demoFrames{dir: "test", frames: []*image.RGBA{solidFrame(100)}, interval: time.Second}
+			reads := 0
+			cfg := &Config{
+				MinConfidence: 60,
+				Frames:        frames,
+				OCRConfidence: func(string) (string, float64, error) {
+					r := tt.reads[min(reads, len(tt.reads)-1)]
+					reads++
+					return r.text, r.confidence, nil
+				},
+			}
+			runScriptedLoop(t, cfg, []string{""})
+			if reads != tt.wantReads {
+				t.Errorf("loop stopped after %d reads, want %d", reads, tt.wantReads)
+			}
+			if frames.next != tt.wantRerolls {
+				t.Errorf("rerolled %d times, want %d", frames.next, tt.wantRerolls)
+			}
+		})
+	}
+}
+
+func TestEmptyPanelNeverSucceeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+	}{
+		{"no text", []string{""}},
+		{"panel art only", []string{"~~ .. ~~\n", "'' ,, ''\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := &demoFrames{dir: "test", frames: []*image.RGBA{solidFrame(100)}, interval: time.Second}
+			cfg := &Config{Frames: frames}
+			if got := runScriptedLoop(t, cfg, tt.texts); got != maxBadReads {
+				t.Errorf("loop stopped after %d reads, want to give up after %d bad reads", got, maxBadReads)
+			}
+			if frames.next != 0 {
+				t.Errorf("rerolled %d times on an empty panel, want 0", frames.next)
+			}
+		})
+	}
+}
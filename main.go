@@ -1,44 +1,24 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
+	"maple_flame/internal/automation"
 	"maple_flame/internal/ocr"
 	"maple_flame/internal/screenshot"
 	"maple_flame/internal/window"
 )
 
-// Windows API for sending keypress and mouse clicks
-var (
-	user32               = syscall.NewLazyDLL("user32.dll")
-	procKeyboardEvent    = user32.NewProc("keybd_event")
-	procFindWindow       = user32.NewProc("FindWindowW")
-	procPostMessage      = user32.NewProc("PostMessageW")
-	procSetCursorPos     = user32.NewProc("SetCursorPos")
-	procMouseEvent       = user32.NewProc("mouse_event")
-	procGetAsyncKeyState = user32.NewProc("GetAsyncKeyState")
-)
-
 const (
-	VK_SPACE       = 0x20
-	VK_RETURN      = 0x0D
-	VK_CONTROL     = 0x11
-	VK_F1          = 0x70
-	WM_KEYDOWN     = 0x0100
-	WM_KEYUP       = 0x0101
-	INPUT_KEYBOARD = 1
-	
-	// Mouse event constants
-	MOUSEEVENTF_LEFTDOWN = 0x0002
-	MOUSEEVENTF_LEFTUP   = 0x0004
-	
 	// Global capture area settings
 	CAPTURE_X      = 530  // X position relative to MapleStory window
 	CAPTURE_Y      = 345  // Y position relative to MapleStory window  
@@ -50,18 +30,11 @@ const (
 	CLICK_OFFSET_Y = 720  // Click Y offset from window
 )
 
-type INPUT struct {
-	Type uint32
-	Ki   KEYBDINPUT
-}
+// windowPollInterval is how often --wait looks for the game window
+const windowPollInterval = 500 * time.Millisecond
 
-type KEYBDINPUT struct {
-	VirtualKeyCode uint16
-	ScanCode       uint16
-	Flags          uint32
-	Time           uint32
-	ExtraInfo      uintptr
-}
+// focusTimeout is how long to wait for MapleStory to take focus before clicking
+const focusTimeout = 1 * time.Second
 
 // MainStat enum for the four main stats
 type MainStat int
@@ -105,53 +78,681 @@ func parseMainStat(s string) (MainStat, error) {
 	}
 }
 
-// setupLogging configures logging to write to both console and temp/flame.log
-func setupLogging() {
+// setupLogging configures logging to write to both console and flame.log in
+// tempDir.
+// When console is false the output only goes to the log file. It returns the
+// original stdout so callers can still write to the real console, and a flush
+// function that waits for everything printed so far to reach the log.
+// With keepTemp the previous run's files are archived first instead of the
+// log being overwritten. A logMaxSize (bytes) above 0 appends to the log
+// instead, rolling it over at that size and keeping logKeep old files.
+func setupLogging(tempDir string, console, keepTemp bool, logMaxSize int64, logKeep int) (*os.File, func()) {
+	originalStdout := os.Stdout
+	noFlush := func() {}
+
 	// Create temp directory if it doesn't exist
-	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		fmt.Printf("Failed to create temp directory: %v\n", err)
-		return
+		return originalStdout, noFlush
+	}
+
+	// A failed archive is reported once the log is running, so the warning
+	// ends up in it too; the run itself still gets logged
+	archived := ""
+	var archiveErr error
+	if keepTemp {
+		archived, archiveErr = archivePreviousRun(tempDir)
 	}
 
-	// Create log file (same file each time, clear on each run)
+	// Create log file (same file each time, clear on each run), or with a
+	// size limit keep appending and roll it over when it gets too big
 	logPath := filepath.Join(tempDir, "flame.log")
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	var logFile io.WriteCloser
+	var err error
+	if logMaxSize > 0 {
+		logFile, err = openRotatingWriter(logPath, logMaxSize, logKeep)
+	} else {
+		logFile, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	}
 	if err != nil {
 		fmt.Printf("Failed to create log file: %v\n", err)
-		return
+		return originalStdout, noFlush
 	}
 
-	// Create multi-writer to write to both original stdout and file
-	originalStdout := os.Stdout
-	multiWriter := io.MultiWriter(originalStdout, logFile)
+	// Create multi-writer to write to both original stdout and file.
+	// Color codes are only meaningful on the console, so the file gets plain text.
+	var multiWriter io.Writer = newANSIStripWriter(logFile)
+	if console {
+		multiWriter = io.MultiWriter(originalStdout, multiWriter)
+	}
 	
 	// Create a pipe to redirect stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 	
 	// Start goroutine to copy from pipe to multi-writer
+	copied := make(chan struct{})
 	go func() {
+		defer close(copied)
 		defer logFile.Close()
 		io.Copy(multiWriter, r)
 	}()
 	
 	fmt.Printf("📝 Logging enabled: %s\n", logPath)
+	if archiveErr != nil {
+		fmt.Printf("⚠️ Failed to archive previous run: %v\n", archiveErr)
+	} else if archived != "" {
+		fmt.Printf("🗄️ Previous run archived to %s\n", archived)
+	}
+
+	flush := func() {
+		// Closing the pipe ends the copy once all buffered output is written
+		os.Stdout = originalStdout
+		w.Close()
+		<-copied
+	}
+	return originalStdout, flush
 }
 
-func main() {
-	// Setup logging to both console and file
-	setupLogging()
+// archivePreviousRun moves the files a previous run left in tempDir into
+// tempDir/archive/<time of that run>, and returns that folder ("" if there
+// was nothing to archive). Subfolders, including earlier archives, stay put.
+// If a move fails the error names that file; the files before it have
+// already been moved.
+func archivePreviousRun(tempDir string) (string, error) {
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		return "", err
+	}
 
-	fmt.Println("MapleStory Auto Flame Reroller")
-	fmt.Println("=============================")
+	var files []string
+	runTime := time.Time{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		// Name the archive after the last write of that run
+		if info.ModTime().After(runTime) {
+			runTime = info.ModTime()
+		}
+		files = append(files, entry.Name())
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	archiveDir := filepath.Join(tempDir, "archive", runTime.Format("20060102_150405"))
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return "", err
+	}
+	for _, name := range files {
+		if err := os.Rename(filepath.Join(tempDir, name), filepath.Join(archiveDir, name)); err != nil {
+			return archiveDir, fmt.Errorf("moving %s to %s: %w", name, archiveDir, err)
+		}
+	}
+	return archiveDir, nil
+}
+
+// sessionOutputDir returns the folder a run writes its files to: base, or
+// with session a session_<start time> folder inside it
+func sessionOutputDir(base string, session bool, start time.Time) string {
+	if !session {
+		return base
+	}
+	return filepath.Join(base, "session_"+start.Format("20060102_150405"))
+}
 
+func main() {
 	// Parse command-line flags
 	modeFlag := flag.String("mode", "", "Mode: armor or weapon")
 	mainStatFlag := flag.String("MAIN_STAT", "", "Main stat to target for armor mode (STR, DEX, INT, LUK)")
 	weaponTypeFlag := flag.String("type", "", "Weapon type for weapon mode (ATT, MATT)")
+	plateauFlag := flag.Int("plateau", 0, "Stop after N attempts without a new best score (0 = off)")
+	cropTopFlag := flag.Int("crop-top", 0, "Pixels to trim from the top of the capture before OCR")
+	cropBottomFlag := flag.Int("crop-bottom", 0, "Pixels to trim from the bottom of the capture before OCR")
+	cropLeftFlag := flag.Int("crop-left", 0, "Pixels to trim from the left of the capture before OCR")
+	cropRightFlag := flag.Int("crop-right", 0, "Pixels to trim from the right of the capture before OCR")
+	trendWindowFlag := flag.Int("trend-window", 0, "Abort if the average score over N rerolls stays far below the starting score (0 = off)")
+	trendRatioFlag := flag.Float64("trend-ratio", 0.5, "Fraction of the starting score used by --trend-window")
+	actionLogFlag := flag.Bool("action-log", false, "Record every click, key press, capture and decision to temp/actions_<timestamp>.jsonl")
+	targetScoreFlag := flag.Int("target-score", 0, "Stop when the score reaches N, whatever the mode scores (lines, stat sum or class score); overrides --max-lines and --min-stat-sum (0 = off)")
+	baselineFlag := flag.String("baseline-image", "", "Compare one capture against a known-good reference PNG and exit")
+	stopKeyFlag := flag.String("stop-key", "ctrl+f1", "Comma-separated key combos that stop the loop, any of which works (e.g. ctrl+f1,esc)")
+	saveEnhancedFlag := flag.Bool("save-enhanced", false, "OCR the enhanced image and keep it next to the raw capture (debug_ss_1_enhanced.png)")
+	parkCursorFlag := flag.Bool("park-cursor", false, "Move the cursor away from the stats before each capture")
+	parkXFlag := flag.Int("park-x", 10, "Cursor park X offset from the window (with --park-cursor)")
+	parkYFlag := flag.Int("park-y", 10, "Cursor park Y offset from the window (with --park-cursor)")
+	checkFlag := flag.Bool("check", false, "Validate tesseract, window, capture region and click target, then exit (exit status 1 on a failure)")
+	confirmRegionFlag := flag.String("confirm-region", "", "x,y,width,height of the confirmation dialog; Enter is pressed only when it appears")
+	confirmTimeoutFlag := flag.Duration("confirm-timeout", 2*time.Second, "How long to wait for the confirmation dialog (with --confirm-region)")
+	compareFlag := flag.String("compare-configs", "", "Score one capture under several targets, e.g. STR,DEX,ATT, and exit")
+	compareImageFlag := flag.String("compare-image", "", "PNG to use with --compare-configs instead of a live capture")
+	choicesFlag := flag.String("choices", "", "Score several stat boxes, x,y,w,h;x,y,w,h;..., for --MAIN_STAT or --type and report the best, then exit")
+	choiceClickFlag := flag.Bool("choice-click", false, "Click the best box found by --choices")
+	replFlag := flag.Bool("repl", false, "Read OCR text from stdin and print how it is parsed (no capture)")
+	watchdogFlag := flag.Duration("watchdog-timeout", 0, "Recover if no attempt completes within this time, e.g. 2m (0 = off)")
+	watchdogAbortFlag := flag.Bool("watchdog-abort", false, "Abort instead of re-activating the window when the watchdog fires")
+	ocrFixesFlag := flag.String("ocr-fixes", "", "File of from=to OCR text fixes applied before counting stats")
+	milestoneFlag := flag.Int("milestone-every", 0, "Save a permanent screenshot every N attempts (0 = off)")
+	windowTitleFlag := flag.String("window-title", window.DefaultTitle, "Title of the game window (any language)")
+	primeMainFlag := flag.Bool("prime-main", false, "Armor mode: also require the main stat on the prime (first) line")
+	adaptiveDelayFlag := flag.Bool("adaptive-delay", false, "Tune the wait after each reroll from how fast the stats actually update")
+	delayMinFlag := flag.Duration("delay-min", 300*time.Millisecond, "Shortest wait allowed by --adaptive-delay")
+	delayMaxFlag := flag.Duration("delay-max", 3*time.Second, "Longest wait allowed by --adaptive-delay")
+	blankRetriesFlag := flag.Int("blank-retries", 3, "Re-capture up to N times while the region is a single color (transition frame)")
+	itemNameFlag := flag.String("item-name", "", "Label for the item being rerolled, used in logs and results")
+	itemRegionFlag := flag.String("item-name-region", "", "x,y,width,height to read the item name from (falls back to --item-name)")
+	minStatSumFlag := flag.Int("min-stat-sum", 0, "Armor mode: stop when main stat + All Stats % x --all-stat-weight reaches N (0 = count lines)")
+	allStatWeightFlag := flag.Float64("all-stat-weight", 10, "Main stat worth of 1% All Stats for --min-stat-sum")
+	autoRestoreFlag := flag.Bool("auto-restore", false, "Restore the game window before each capture if it is minimized")
+	unchangedEpsilonFlag := flag.Int("unchanged-epsilon", 0, "Scores within N of the best count as unchanged for --plateau")
+	stopExprFlag := flag.String("stop-expr", "", "Custom stop rule over stat values, e.g. \"STR >= 30 || ALLSTAT >= 6\" (replaces the built-in rule)")
+	keepTempFlag := flag.Bool("keep-temp", false, "Archive the previous run's log and images to temp/archive/ instead of overwriting them")
+	maxLinesFlag := flag.Int("max-lines", defaultRequiredLines, "Number of matching stat lines required to stop")
+	minLineValueFlag := flag.Int("min-line-value", 0, "Only count stat lines whose value is at least V (0 = any value)")
+	verifyFramesFlag := flag.Int("verify-frames", 0, "Re-capture N more frames and require them all to agree before stopping on a success (0 = off)")
+	keyHoldFlag := flag.Int("key-hold-ms", 50, "How long each key press is held down, in milliseconds")
+	activateDelayFlag := flag.Int("activate-delay-ms", 100, "Milliseconds to let the window settle after it takes focus, before clicking")
+	classFlag := flag.String("class", "", "Armor mode: score --min-stat-sum with a class profile (warrior, bowman, mage, thief, pirate, xenon)")
+	regionScanFlag := flag.Bool("region-scan", false, "After repeated bad reads, search the whole window for the flame stats and suggest new capture offsets")
+	flameBudgetFlag := flag.Int("flame-budget", 0, "Stop after N flames are consumed (rerolls that changed the stats; 0 = off)")
+	statCapsFlag := flag.String("stat-caps", "", "Stop once a stat reaches its cap, e.g. STR=48,ALLSTAT=6 (names as in --stop-expr)")
+	debugClickFlag := flag.Bool("debug-click", false, "Save a 50x50 capture around the reroll click on every attempt (temp/click_debug_flame_<n>.png)")
+	textBandFlag := flag.Bool("text-band", false, "Crop each capture to the rows containing text before OCR, absorbing small vertical misalignment")
+	tessdataDirFlag := flag.String("tessdata-dir", "", "Directory with custom tessdata to pass to tesseract")
+	tessConfigFlag := flag.String("tess-config", "", "Tesseract config file name to apply to every OCR run")
+	confirmXFlag := flag.Int("confirm-x", -1, "X offset of a confirm button to click after the reroll click, instead of pressing Enter")
+	confirmYFlag := flag.Int("confirm-y", -1, "Y offset of the confirm button (with --confirm-x)")
+	demoFlag := flag.String("demo", "", "Run the reroll display on the PNG stat boxes in this folder instead of the game (needs --mode)")
+	demoIntervalFlag := flag.Duration("demo-interval", 2*time.Second, "Time each --demo frame is shown")
+	rebaselineKeyFlag := flag.String("rebaseline-key", "", "Key combo that resets the stuck, plateau and trend tracking mid-session (e.g. ctrl+f2)")
+	anchorFlag := flag.String("anchor", "window", "Measure capture and click offsets from the window's top-left corner (window), the screen center (screen-center) or the client area inside the title bar and borders (client)")
+	maxOCRSizeFlag := flag.Int("max-ocr-size", screenshot.DefaultMaxEnhancedSize, "Largest width/height in pixels of an upscaled OCR image; the upscale shrinks to fit (0 = no cap)")
+	compareSessionsFlag := flag.String("compare-sessions", "", "Compare two --stream logs (A.jsonl,B.jsonl) side by side and exit")
+	stableFramesFlag := flag.Int("stable-frames", 0, "After a reroll, wait until this many consecutive captures match instead of a fixed delay (0 = off)")
+	stableTimeoutFlag := flag.Duration("stable-timeout", 3*time.Second, "Longest wait for --stable-frames before reading anyway")
+	exactMainFlag := flag.Int("exact-main", 0, "Armor: stop only when a main stat line rolls exactly this value, e.g. a perfect roll (0 = off)")
+	stuckGraceFlag := flag.Int("stuck-grace", 0, "Ignore the first N read attempts for stuck detection while the UI settles")
+	invertFlag := flag.String("invert", "off", "OCR inverted colors for dark-on-light panels: off, on, or auto (retry inverted on a poor read)")
+	montageFlag := flag.Int("montage", 0, "At session end, stack the last N captures into temp/montage.png labeled with attempt numbers (0 = off)")
+	minConfidenceFlag := flag.Float64("min-confidence", 0, "Re-capture instead of deciding when the mean OCR word confidence (0-100) is below this (0 = off)")
+	maxScoreFlag := flag.Int("max-score", 0, "Best score the item can roll; shows each score as a 0-100 roll quality (0 = off)")
+	panicFlag := flag.Bool("panic-on-unknown", false, "Abort before each click if text outside the reroll flow (an unexpected dialog) is on screen")
+	panicRegionFlag := flag.String("panic-region", "", "Area checked by --panic-on-unknown as x,y,width,height, e.g. where dialogs pop up (required with it)")
+	panicAllowFlag := flag.String("panic-allow", "", "Extra comma-separated words --panic-on-unknown should accept")
+	tallPanelFlag := flag.Bool("tall-panel", false, "Also read the area just below the capture region, for items with more stat lines than fit")
+	soundsFlag := flag.Bool("sounds", false, "Play a distinct beep pattern on success, stuck, error and each new best score")
+	soundSuccessFlag := flag.String("sound-success", "", "Sound on success: a .wav path, a freq:ms,... beep pattern, or off")
+	soundStuckFlag := flag.String("sound-stuck", "", "Sound when stopping on stuck stats (.wav, freq:ms pattern, or off)")
+	soundErrorFlag := flag.String("sound-error", "", "Sound when stopping on an error (.wav, freq:ms pattern, or off)")
+	soundBestFlag := flag.String("sound-best", "", "Sound on each new best score (.wav, freq:ms pattern, or off)")
+	rerollDelayFlag := flag.String("reroll-delay", defaultRerollDelay.String(), "Wait after each reroll: 2s, a uniform range like 1.5s-2.5s, or normal:2s,300ms")
+	clickSettleFlag := flag.String("click-settle", defaultClickSettle.String(), "Wait after the reroll click before confirming (same forms as --reroll-delay)")
+	keyHoldSpecFlag := flag.String("key-hold", "", "Key press hold time as a delay spec, e.g. 40ms-70ms (overrides --key-hold-ms)")
+	delaySeedFlag := flag.Int64("delay-seed", 0, "Seed for random delays, to reproduce a run's timing (0 = seed from the clock)")
+	dpiScaleFlag := flag.String("dpi-scale", "off", "Scale click positions to physical pixels on high-DPI displays: off, auto (from the window's DPI), or a percentage like 150")
+	selfTestFlag := flag.Bool("self-test", false, "Run OCR on a bundled reference image and report whether the stats read correctly, then exit (exit status 1 on FAIL)")
+	backgroundCaptureFlag := flag.Bool("background-capture", false, "Capture the game window with PrintWindow so it can be read while covered by other windows")
+	takeoverKeyFlag := flag.String("takeover-key", "", "Pause capturing and clicking while this key is held, e.g. shift (hold to take over by hand)")
+	refResolutionFlag := flag.String("ref-resolution", "", "Window size the capture and click offsets were measured at, e.g. 1280x720; offsets are scaled to the actual window (empty = use as-is)")
+	sessionDirFlag := flag.Bool("session-dir", false, "Write this run's log, images and action log to its own temp/session_<timestamp>/ folder")
+	waitFlag := flag.Duration("wait", 0, "Keep looking for the game window at startup for up to this long, e.g. 60s (0 = fail right away)")
+	backgroundFlag := flag.Bool("background", false, "Post the reroll clicks and key presses to the game window instead, without taking focus or moving the cursor")
+	uiCheckFlag := flag.String("ui-check-pixel", "", "x,y=RRGGBB pixel that must match before each attempt (pauses while the reroll UI is closed)")
+	logMaxSizeFlag := flag.Int("log-max-size", 0, "Keep appending to temp/flame.log and roll it over at this many MB (0 = start a fresh log each run)")
+	logKeepFlag := flag.Int("log-keep", 3, "Old log files kept by --log-max-size (flame.log.1, flame.log.2, ...)")
+	streamFlag := flag.String("stream", "", "Write each attempt as a JSON line to stdout (-) or to a named pipe/file path")
 	flag.Parse()
 
+	// Each session can get its own folder so runs don't overwrite each other
+	outputDir := sessionOutputDir("temp", *sessionDirFlag, time.Now())
+	screenshot.SetOutputDir(outputDir)
+
+	// --keep-temp moves flame.log and its rolled files away every run, so a
+	// rolling log would never get past its first file
+	if *keepTempFlag && *logMaxSizeFlag > 0 {
+		fmt.Println("❌ Error: --keep-temp archives the log each run and cannot be used with --log-max-size")
+		return
+	}
+
+	// Setup logging to both console and file (file only when streaming to stdout)
+	console, flushLogs := setupLogging(outputDir, *streamFlag != "-", *keepTempFlag, int64(*logMaxSizeFlag)*1024*1024, *logKeepFlag)
+
+	// exitCode is set by the checks that report pass/fail, so scripts can tell
+	// a failed --check or --self-test apart. This defer runs last, after the
+	// other cleanup, since os.Exit skips any defer still pending.
+	exitCode := 0
+	defer func() {
+		flushLogs()
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	fmt.Println("MapleStory Auto Flame Reroller")
+	fmt.Println("=============================")
+
+	window.SetTargetTitle(*windowTitleFlag)
+
+	ocr.SetKeepEnhanced(*saveEnhancedFlag)
+
+	if *tessdataDirFlag != "" {
+		if info, err := os.Stat(*tessdataDirFlag); err != nil || !info.IsDir() {
+			fmt.Printf("❌ Error: --tessdata-dir %s is not a directory\n", *tessdataDirFlag)
+			return
+		}
+	}
+	ocr.SetTesseractOptions(*tessdataDirFlag, *tessConfigFlag)
+
+	if *maxOCRSizeFlag < 0 {
+		fmt.Println("❌ Error: --max-ocr-size cannot be negative")
+		return
+	}
+	screenshot.SetMaxEnhancedSize(*maxOCRSizeFlag)
+
+	anchor, err := window.ParseAnchor(*anchorFlag)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+	window.SetAnchor(anchor)
+	screenshot.SetBackgroundCapture(*backgroundCaptureFlag)
+
+	// auto needs the window, which --wait may still be waiting for; it is
+	// read by applyAutoDPIScale once the window is found
+	autoDPIScale := false
+	switch *dpiScaleFlag {
+	case "", "off":
+	case "auto":
+		autoDPIScale = true
+	default:
+		percent, err := strconv.Atoi(strings.TrimSuffix(*dpiScaleFlag, "%"))
+		if err != nil || percent < 100 {
+			fmt.Printf("❌ Error: invalid --dpi-scale %q (use off, auto, or a percentage of 100 or more)\n", *dpiScaleFlag)
+			return
+		}
+		automation.SetCursorScale(float64(percent) / 100)
+	}
+
+	stopKeys, err := automation.ParseStopKeys(*stopKeyFlag)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+	automation.SetStopKeys(stopKeys)
+
+	if *actionLogFlag {
+		actionLogger, err := automation.NewActionLogger(outputDir)
+		if err != nil {
+			fmt.Printf("⚠️ Action log disabled: %v\n", err)
+		} else {
+			defer actionLogger.Close()
+			automation.SetActionLogger(actionLogger)
+			fmt.Printf("📝 Action log enabled: %s\n", actionLogger.Path())
+		}
+	}
+
+	cfg := &Config{
+		Plateau: *plateauFlag,
+		CropMargins: screenshot.Margins{
+			Top:    *cropTopFlag,
+			Bottom: *cropBottomFlag,
+			Left:   *cropLeftFlag,
+			Right:  *cropRightFlag,
+		},
+		TrendWindow:      *trendWindowFlag,
+		TrendRatio:       *trendRatioFlag,
+		MilestoneEvery:   *milestoneFlag,
+		SaveEnhanced:     *saveEnhancedFlag,
+		ParkCursor:       *parkCursorFlag,
+		ParkX:            *parkXFlag,
+		ParkY:            *parkYFlag,
+		WatchdogTimeout:  *watchdogFlag,
+		WatchdogAbort:    *watchdogAbortFlag,
+		ConfirmTimeout:   *confirmTimeoutFlag,
+		AdaptiveDelay:    *adaptiveDelayFlag,
+		DelayMin:         *delayMinFlag,
+		DelayMax:         *delayMaxFlag,
+		BlankRetries:     *blankRetriesFlag,
+		ItemName:         *itemNameFlag,
+		MinStatSum:       *minStatSumFlag,
+		AllStatWeight:    *allStatWeightFlag,
+		AutoRestore:      *autoRestoreFlag,
+		ActivateDelay:    time.Duration(*activateDelayFlag) * time.Millisecond,
+		RegionScan:       *regionScanFlag,
+		FlameBudget:      *flameBudgetFlag,
+		DebugClick:       *debugClickFlag,
+		TextBand:         *textBandFlag,
+		ConfirmX:         *confirmXFlag,
+		ConfirmY:         *confirmYFlag,
+		StableFrames:     *stableFramesFlag,
+		StableTimeout:    *stableTimeoutFlag,
+		ExactMain:        *exactMainFlag,
+		StuckGrace:       *stuckGraceFlag,
+		Montage:          *montageFlag,
+		MinConfidence:    *minConfidenceFlag,
+		MaxScore:         *maxScoreFlag,
+		PanicOnUnknown:   *panicFlag,
+		TallPanel:        *tallPanelFlag,
+		WaitForWindow:    *waitFlag,
+		Background:       *backgroundFlag,
+		AutoDPIScale:     autoDPIScale,
+		KeyHold:          fixedDelay(time.Duration(*keyHoldFlag) * time.Millisecond),
+		VerifyFrames:     *verifyFramesFlag,
+		RequiredLines:    *maxLinesFlag,
+		MinLineValue:     *minLineValueFlag,
+		UnchangedEpsilon: *unchangedEpsilonFlag,
+		TargetScore:      *targetScoreFlag,
+	}
+	shareClock(cfg.clock())
+
+	if *itemRegionFlag != "" {
+		region, err := screenshot.ParseRegion(*itemRegionFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.ItemNameRegion = region
+	}
+
+	if *streamFlag != "" {
+		stream, err := openResultStream(*streamFlag, console)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		defer stream.Close()
+		cfg.Stream = stream
+	}
+
+	seed := *delaySeedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	delayRNG := rand.New(rand.NewSource(seed))
+	for _, d := range []struct {
+		spec   string
+		target *Delay
+	}{
+		{*rerollDelayFlag, &cfg.RerollDelay},
+		{*clickSettleFlag, &cfg.ClickSettle},
+		{*keyHoldSpecFlag, &cfg.KeyHold},
+	} {
+		if d.spec == "" {
+			continue
+		}
+		delay, err := parseDelay(d.spec, delayRNG)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		*d.target = delay
+	}
+
+	if cfg.KeyHold.Min() <= 0 {
+		fmt.Println("❌ Error: key hold time must be positive")
+		return
+	}
+
+	if (cfg.ConfirmX < 0) != (cfg.ConfirmY < 0) {
+		fmt.Println("❌ Error: --confirm-x and --confirm-y must be given together")
+		return
+	}
+
+	if cfg.StableFrames == 1 || cfg.StableFrames < 0 {
+		fmt.Println("❌ Error: --stable-frames must be 0 (off) or at least 2")
+		return
+	}
+	if cfg.StableFrames > 0 && cfg.AdaptiveDelay {
+		fmt.Println("❌ Error: --stable-frames and --adaptive-delay both control the post-reroll wait; use one")
+		return
+	}
+	if cfg.StableFrames > 0 && cfg.StableTimeout <= 0 {
+		fmt.Println("❌ Error: --stable-timeout must be positive")
+		return
+	}
+
+	if cfg.ExactMain < 0 {
+		fmt.Println("❌ Error: --exact-main cannot be negative")
+		return
+	}
+	if cfg.ExactMain > 0 && cfg.MinStatSum > 0 {
+		fmt.Println("❌ Error: --exact-main and --min-stat-sum are different stop rules; use one")
+		return
+	}
+
+	if cfg.StuckGrace < 0 {
+		fmt.Println("❌ Error: --stuck-grace cannot be negative")
+		return
+	}
+
+	if cfg.Montage < 0 {
+		fmt.Println("❌ Error: --montage cannot be negative")
+		return
+	}
+
+	if cfg.MinConfidence < 0 || cfg.MinConfidence > 100 {
+		fmt.Println("❌ Error: --min-confidence must be between 0 and 100")
+		return
+	}
+
+	if cfg.MinConfidence > 0 && cfg.SaveEnhanced {
+		fmt.Println("❌ Error: --min-confidence reads the plain capture and can't be combined with --save-enhanced")
+		return
+	}
+
+	if cfg.MaxScore < 0 {
+		fmt.Println("❌ Error: --max-score cannot be negative")
+		return
+	}
+
+	if cfg.Background && cfg.ParkCursor {
+		fmt.Println("❌ Error: --park-cursor moves the cursor, which --background is meant to avoid")
+		return
+	}
+
+	if cfg.WaitForWindow < 0 {
+		fmt.Println("❌ Error: --wait cannot be negative")
+		return
+	}
+
+	if cfg.TargetScore < 0 {
+		fmt.Println("❌ Error: --target-score must be 0 (off) or more")
+		return
+	}
+
+	// The history also holds the baseline attempt, which is never averaged
+	if cfg.TrendWindow < 0 || cfg.TrendWindow >= historySize {
+		fmt.Printf("❌ Error: --trend-window must be between 0 (off) and %d\n", historySize-1)
+		return
+	}
+
+	if cfg.RequiredLines < 1 {
+		fmt.Println("❌ Error: --max-lines must be at least 1")
+		return
+	}
+
+	if *classFlag != "" {
+		profile, err := parseClassProfile(*classFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		if cfg.MinStatSum <= 0 {
+			fmt.Println("❌ Error: --class needs --min-stat-sum to know when to stop")
+			return
+		}
+		cfg.Class = profile
+	}
+
+	if *statCapsFlag != "" {
+		caps, err := parseStatCaps(*statCapsFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.StatCaps = caps
+	}
+
+	if *rebaselineKeyFlag != "" {
+		hotkey, err := automation.NewHotkey(*rebaselineKeyFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.RebaselineKey = hotkey
+	}
+
+	if *refResolutionFlag != "" {
+		width, height, err := parseResolution(*refResolutionFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		window.SetReferenceSize(width, height)
+	}
+
+	if *takeoverKeyFlag != "" {
+		key, err := automation.NewHoldKey(*takeoverKeyFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.TakeoverKey = key
+	}
+
+	invert, err := parseInvertMode(*invertFlag)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+	cfg.Invert = invert
+
+	sounds, err := newSoundSet(*soundsFlag, map[outcome]string{
+		outcomeSuccess: *soundSuccessFlag,
+		outcomeStuck:   *soundStuckFlag,
+		outcomeError:   *soundErrorFlag,
+		outcomeBest:    *soundBestFlag,
+	})
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+	cfg.Sounds = sounds
+
+	if *stopExprFlag != "" {
+		expr, err := parseStopExpr(*stopExprFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.StopExpr = expr
+	}
+
+	if *uiCheckFlag != "" {
+		check, err := parseUIPixelCheck(*uiCheckFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.UICheck = &check
+	}
+
+	if *panicRegionFlag != "" {
+		region, err := screenshot.ParseRegion(*panicRegionFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.PanicRegion = region
+	}
+	// The whole window has the title bar, chat, names and HUD on it, all of
+	// which would read as unknown words
+	if cfg.PanicOnUnknown && cfg.PanicRegion.IsZero() {
+		fmt.Println("❌ Error: --panic-on-unknown needs --panic-region=x,y,width,height around where dialogs appear")
+		return
+	}
+	if cfg.PanicOnUnknown {
+		cfg.PanicAllow = panicAllowlist(strings.Split(*panicAllowFlag, ","))
+	}
+
+	if *confirmRegionFlag != "" {
+		region, err := screenshot.ParseRegion(*confirmRegionFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.ConfirmRegion = region
+	}
+
+	if *ocrFixesFlag != "" {
+		aliases, err := ocr.LoadStatAliases(*ocrFixesFlag)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		cfg.StatAliases = aliases
+		fmt.Printf("Loaded %d OCR fixes from %s\n", len(aliases), *ocrFixesFlag)
+	}
+
+	if *demoFlag != "" {
+		if err := enableDemo(cfg, *demoFlag, *demoIntervalFlag); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+	}
+
+	if *compareSessionsFlag != "" {
+		runCompareSessions(*compareSessionsFlag)
+		return
+	}
+
+	if *selfTestFlag {
+		if !runSelfTest(cfg) {
+			exitCode = 1
+		}
+		return
+	}
+
+	if *checkFlag {
+		if !runSetupCheck(cfg) {
+			exitCode = 1
+		}
+		return
+	}
+
+	if *compareFlag != "" {
+		runCompareConfigs(*compareFlag, *compareImageFlag, cfg)
+		return
+	}
+
+	if *choicesFlag != "" {
+		target := *mainStatFlag
+		if target == "" {
+			target = *weaponTypeFlag
+		}
+		if target == "" {
+			fmt.Println("❌ Error: --choices needs --MAIN_STAT or --type to score the boxes")
+			return
+		}
+		runChoices(*choicesFlag, target, *choiceClickFlag, cfg)
+		return
+	}
+
+	if *replFlag {
+		runRepl(os.Stdin, os.Stdout, *mainStatFlag, *weaponTypeFlag, cfg)
+		return
+	}
+
+	if *baselineFlag != "" {
+		runBaselineCheck(*baselineFlag, cfg)
+		return
+	}
+
 	// Check if no parameters provided
 	if len(flag.Args()) == 0 && *modeFlag == "" {
 		fmt.Println("❌ Error: No parameters provided!")
@@ -161,40 +762,132 @@ func main() {
 		fmt.Println()
 		fmt.Println("🛡️  ARMOR MODE:")
 		fmt.Println("   Target main stats (STR/DEX/INT/LUK) + All Stats")
-		fmt.Println("   Stops when 2+ lines contain the main stat")
+		fmt.Println("   Stops when 2+ lines contain the main stat (--max-lines)")
 		fmt.Println()
 		fmt.Println("   Examples:")
 		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=STR")
 		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=DEX")
 		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=INT")
 		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=LUK")
+		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=STR --prime-main  (STR on first line)")
+		fmt.Println("     ./maple_flame --mode=armor --MAIN_STAT=STR --min-stat-sum=120  (STR + All Stats % x 10)")
 		fmt.Println()
 		fmt.Println("⚔️  WEAPON MODE:")
 		fmt.Println("   Target ATT/MATT + Boss Damage + Ignore Defense")
-		fmt.Println("   Stops when 2+ weapon stat lines found")
+		fmt.Println("   Stops when 2+ weapon stat lines found (--max-lines)")
 		fmt.Println()
 		fmt.Println("   Examples:")
 		fmt.Println("     ./maple_flame --mode=weapon --type=ATT   (Physical weapons)")
 		fmt.Println("     ./maple_flame --mode=weapon --type=MATT  (Magic weapons)")
 		fmt.Println()
+		fmt.Println("⚙️  OPTIONS:")
+		fmt.Println("   --target-score=N  Stop once the score reaches N (takes precedence over --max-lines")
+		fmt.Println("                and --min-stat-sum; --stop-expr replaces it)")
+		fmt.Println("   --plateau=N  Stop after N attempts without a new best score")
+		fmt.Println("   --unchanged-epsilon=N  Ignore score gains of N or less for --plateau")
+		fmt.Println("   --crop-top/--crop-bottom/--crop-left/--crop-right=N")
+		fmt.Println("                Trim N pixels of panel border before OCR")
+		fmt.Println("   --milestone-every=N  Keep a screenshot every N attempts")
+		fmt.Println("   --ocr-fixes=F  File of from=to OCR text fixes (e.g. Al Stats=All Stats)")
+		fmt.Println("   --baseline-image=F  Compare a capture with reference PNG F and exit")
+		fmt.Println("   --save-enhanced  OCR the enhanced image and keep it for inspection")
+		fmt.Println("   --park-cursor  Move the cursor to --park-x/--park-y before capturing")
+		fmt.Println("   --check  Verify your setup without rerolling")
+		fmt.Println("   --compare-configs=STR,DEX,ATT [--compare-image=F]  Score one capture per target")
+		fmt.Println("   --choices=\"x,y,w,h;x,y,w,h\" --MAIN_STAT=STR [--choice-click]  Pick the best of several stat boxes")
+		fmt.Println("   --repl  Paste OCR text and see how it is parsed (uses --MAIN_STAT/--type)")
+		fmt.Println("   --watchdog-timeout=2m  Re-activate the game (or abort with --watchdog-abort) if stalled")
+		fmt.Println("                Neither unblocks a hung capture or OCR; press Ctrl+C twice for that")
+		fmt.Println("   --confirm-region=x,y,w,h  Press Enter only when the confirmation dialog shows")
+		fmt.Println("   --stream=-|PATH  Stream each attempt as JSON lines to stdout or a named pipe")
+		fmt.Println("   --ui-check-pixel=x,y=RRGGBB  Pause while the reroll UI isn't open")
+		fmt.Println("   --adaptive-delay  Learn the reroll wait (bounded by --delay-min/--delay-max)")
+		fmt.Println("   --blank-retries=N  Re-capture single-color transition frames (default 3)")
+		fmt.Println("   --item-name=N / --item-name-region=x,y,w,h  Tag results with the item name")
+		fmt.Println("   --min-stat-sum=N  Armor: stop once main stat + All Stats % x weight reaches N")
+		fmt.Println("   --all-stat-weight=W  Main stat worth of 1% All Stats (default 10)")
+		fmt.Println("   --class=NAME  Score --min-stat-sum with a class profile, e.g. xenon (STR+DEX+LUK)")
+		fmt.Println("   --auto-restore  Restore the game window if it gets minimized")
+		fmt.Println("   --stop-expr=EXPR  Stop when EXPR holds, e.g. \"STR >= 30 || (ALLSTAT >= 5 && BOSS > 0)\"")
+		fmt.Println("                     Names: STR DEX INT LUK ALLSTAT ALLSTATFLAT ATT MATT BOSS IED DAMAGE HP MP SCORE")
+		fmt.Println("   --keep-temp  Archive the previous run's files to temp/archive/<time>/")
+		fmt.Println("   --max-lines=N  Stat lines required to stop (default 2)")
+		fmt.Println("   --min-line-value=V  Only count lines with a value of at least V (e.g. 2 lines >= 15)")
+		fmt.Println("   --verify-frames=N  Re-read N more frames before stopping, to rule out OCR misreads")
+		fmt.Println("   --key-hold-ms=N  Hold each key press for N ms (default 50)")
+		fmt.Println("   --activate-delay-ms=N  Wait N ms after the window takes focus before clicking (default 100)")
+		fmt.Println("   --region-scan  Search the window for the stats when the capture region looks wrong")
+		fmt.Println("   --flame-budget=N  Stop after N flames are used (clicks that didn't change the stats don't count)")
+		fmt.Println("   --stat-caps=STR=48,ALLSTAT=6  Stop once any stat rolls its maximum value")
+		fmt.Println("   --debug-click  Save what is under the reroll click each attempt")
+		fmt.Println("   --text-band  Crop captures to the rows containing text before OCR")
+		fmt.Println("   --tessdata-dir=DIR [--tess-config=NAME]  Use custom tesseract data and config")
+		fmt.Println("   --confirm-x=X --confirm-y=Y  Click a confirm button after rerolling instead of pressing Enter")
+		fmt.Println("   --demo=DIR [--demo-interval=2s]  Replay saved stat boxes through the live display, no game needed")
+		fmt.Println("   --rebaseline-key=ctrl+f2  Hotkey that restarts the stuck/plateau/trend tracking")
+		fmt.Println("   --anchor=screen-center  Measure capture/click offsets from the screen center")
+		fmt.Println("   --anchor=client  Measure capture/click offsets from inside the title bar and borders")
+		fmt.Println("   --max-ocr-size=4000  Cap upscaled OCR images at this many pixels per side")
+		fmt.Println("   --compare-sessions=A.jsonl,B.jsonl  Compare two --stream logs and exit")
+		fmt.Println("   --stable-frames=2 [--stable-timeout=3s]  Wait for the reroll animation to settle")
+		fmt.Println("   --exact-main=40  Armor: accept only a main stat line of exactly this value")
+		fmt.Println("   --stuck-grace=N  Don't check for stuck stats during the first N attempts")
+		fmt.Println("   --log-max-size=MB [--log-keep=3]  Keep a rolling log instead of a fresh one each run (not with --keep-temp)")
+		fmt.Println("   --invert=auto|on|off  OCR inverted colors (auto: retry inverted on a poor read)")
+		fmt.Println("   --montage=N  Save the last N captures as one labeled image at the end")
+		fmt.Println("   --min-confidence=60  Only act on reads tesseract is this confident about")
+		fmt.Println("   --max-score=N  Show scores as a 0-100 roll quality relative to N")
+		fmt.Println("   --panic-on-unknown --panic-region=x,y,w,h [--panic-allow=WORDS]  Abort on unexpected dialogs")
+		fmt.Println("   --tall-panel  Read a second capture below the first for long stat lists")
+		fmt.Println("   --sounds  Beep differently on success, stuck, error and new best")
+		fmt.Println("   --sound-success|stuck|error|best=FILE.wav|880:150,0:80  Pick a sound per outcome")
+		fmt.Println("   --reroll-delay=1.5s-2.5s  Vary the wait after each reroll (also normal:2s,300ms)")
+		fmt.Println("   --click-settle=SPEC --key-hold=SPEC [--delay-seed=N]  Vary click and key timing")
+		fmt.Println("   --dpi-scale=auto|150  Fix clicks landing off-target on scaled displays")
+		fmt.Println("   --self-test  Check tesseract against a bundled reference image and exit")
+		fmt.Println("   --background-capture  Read the game window even while other windows cover it")
+		fmt.Println("   --takeover-key=shift  Pause while the key is held so you can use the mouse")
+		fmt.Println("   --ref-resolution=1280x720  Scale capture/click offsets from this window size")
+		fmt.Println("   --session-dir  Keep each run's files in temp/session_<timestamp>/")
+		fmt.Println("   --wait=60s  Start before the game and wait for its window to appear")
+		fmt.Println("   --background  Reroll without taking focus or moving the mouse (not all clients accept it)")
+		fmt.Println("   --action-log  Record every action taken to temp/actions_*.jsonl")
+		fmt.Println("   --window-title=T  Game window title (default: MapleStory)")
+		fmt.Println("   --trend-window=N --trend-ratio=R")
+		fmt.Println("                Abort if N rerolls average below R x the starting score")
+		fmt.Printf("                (N from 1 to %d)\n", historySize-1)
+		fmt.Println()
 		fmt.Println("🎮 CONTROLS:")
-		fmt.Println("   Ctrl+F1  - Stop gracefully")
-		fmt.Println("   Ctrl+C   - Force quit")
+		fmt.Println("   Ctrl+F1  - Stop gracefully (change with --stop-key=ctrl+f1,esc)")
+		fmt.Println("   Ctrl+C   - Stop gracefully (press twice to force quit)")
 		fmt.Println()
 		fmt.Println("📁 OUTPUT:")
 		fmt.Println("   temp/debug_ss_1.png - Latest screenshot")
+		fmt.Println("   temp/debug_ss_1_enhanced.png - Enhanced image fed to OCR (--save-enhanced)")
 		fmt.Println("   temp/flame.log      - Complete session log")
+		fmt.Println("   temp/milestone_*.png - Milestone screenshots (--milestone-every)")
+		fmt.Println("   temp/montage.png    - Last N captures stacked (--montage)")
 		fmt.Println()
 		return
 	}
 
+	// Ctrl+C stops cleanly from here on, the --wait for the window
+	// included, not only once the loop is running
+	cfg.Stop = &stopRequest{}
+	defer watchInterrupts(cfg.Stop)()
+
+	// Everything in effect for this run, for bug reports and the log
+	fmt.Println("⚙️  Effective configuration:")
+	fmt.Print(cfg)
+	fmt.Println()
+
 	mode := strings.ToLower(strings.TrimSpace(*modeFlag))
 
 	switch mode {
 	case "armor", "armour":
-		runArmorMode(*mainStatFlag)
+		runArmorMode(*mainStatFlag, *primeMainFlag, cfg)
 	case "weapon":
-		runWeaponMode(*weaponTypeFlag)
+		runWeaponMode(*weaponTypeFlag, cfg)
 	default:
 		fmt.Printf("❌ Error: Invalid mode '%s'\n", mode)
 		fmt.Println("Usage:")
@@ -206,7 +899,7 @@ func main() {
 }
 
 // runArmorMode runs the armor flame analysis (original functionality)
-func runArmorMode(mainStatStr string) {
+func runArmorMode(mainStatStr string, primeMain bool, cfg *Config) {
 	fmt.Println("🛡️  ARMOR MODE")
 
 	if mainStatStr == "" {
@@ -224,104 +917,166 @@ func runArmorMode(mainStatStr string) {
 	}
 
 	fmt.Printf("Target main stat: %s\n", MAIN_STAT)
-	fmt.Println("Will stop when 2+ lines contain the main stat (including All Stats)")
+	if cfg.ExactMain > 0 {
+		fmt.Printf("Will stop only when a %s line rolls exactly %d\n", MAIN_STAT, cfg.ExactMain)
+	} else if cfg.MinStatSum > 0 && cfg.Class != nil {
+		fmt.Printf("Will stop when the %s stat score adds up to %d or more\n", cfg.Class, cfg.MinStatSum)
+	} else if cfg.MinStatSum > 0 {
+		fmt.Printf("Will stop when %s + All Stats %% x %.0f adds up to %d or more\n", MAIN_STAT, cfg.AllStatWeight, cfg.MinStatSum)
+	} else {
+		fmt.Printf("Will stop when %d+ lines contain the main stat (including All Stats)%s\n", cfg.RequiredLines, lineValueNote(cfg))
+	}
+	if primeMain {
+		fmt.Println("The prime (first) line must also contain the main stat")
+	}
 	fmt.Println()
 
-	// Step 1: Find MapleStory window
+	handle, ok := prepareRerollWindow(cfg)
+	if !ok {
+		return
+	}
+
+	runRerollLoop(handle, rerollMode{
+		Name: "armor",
+		Evaluate: func(text string) (int, bool) {
+			if cfg.ExactMain > 0 {
+				value := bestMainStatLine(text, MAIN_STAT)
+				return value, value == cfg.ExactMain
+			}
+			if cfg.MinStatSum > 0 && cfg.Class != nil {
+				sum := cfg.Class.Score(text)
+				return sum, sum >= cfg.MinStatSum
+			}
+			if cfg.MinStatSum > 0 {
+				mainStatTotal, allStatPercent := sumMainStatValues(text, MAIN_STAT)
+				sum := statSum(mainStatTotal, allStatPercent, cfg.AllStatWeight)
+				return sum, sum >= cfg.MinStatSum
+			}
+			mainStatCount := countMainStatLines(text, MAIN_STAT, cfg.MinLineValue)
+			return mainStatCount, enoughLines(mainStatCount, cfg)
+		},
+		Describe: func(score int) string {
+			if cfg.ExactMain > 0 {
+				return fmt.Sprintf("Best %s line: %d (want exactly %d)", MAIN_STAT, score, cfg.ExactMain)
+			}
+			if cfg.MinStatSum > 0 && cfg.Class != nil {
+				return fmt.Sprintf("%s stat score: %d / %d", cfg.Class.Name, score, cfg.MinStatSum)
+			}
+			if cfg.MinStatSum > 0 {
+				return fmt.Sprintf("%s stat sum (All Stats %% x %.0f): %d / %d", MAIN_STAT, cfg.AllStatWeight, score, cfg.MinStatSum)
+			}
+			return fmt.Sprintf("%s + All Stats lines found: %d", MAIN_STAT, score)
+		},
+		SuccessMessage: func(score int) string {
+			if cfg.ExactMain > 0 {
+				return fmt.Sprintf("Perfect %s line: exactly %d!", MAIN_STAT, score)
+			}
+			if cfg.MinStatSum > 0 {
+				return fmt.Sprintf("%s stat sum %d reached %d!", MAIN_STAT, score, cfg.MinStatSum)
+			}
+			return fmt.Sprintf("Found %d lines with %s!", score, MAIN_STAT)
+		},
+		Qualifies: func(text string) (bool, string) {
+			if primeMain && !primeLineIsMainStat(text, MAIN_STAT) {
+				return false, fmt.Sprintf("%s is not on the prime (first) line", MAIN_STAT)
+			}
+			return true, ""
+		},
+		RetryMessage: "❌ Not enough main stat lines, rerolling...",
+		ConfigHint:   "check your --MAIN_STAT",
+	}, cfg)
+}
+
+// prepareRerollWindow finds the MapleStory window and checks the click
+// offsets against it. The handle it returns is used for the whole session.
+// In demo mode there is no window and it returns nil.
+func prepareRerollWindow(cfg *Config) (*window.WindowHandle, bool) {
+	if cfg.Frames != nil {
+		fmt.Printf("🎬 Demo mode: %s\n", cfg.Frames)
+		fmt.Printf("Press %s or Ctrl+C to stop\n", automation.StopKeyNames())
+		fmt.Println()
+		return nil, true
+	}
+
+	// Find MapleStory window
 	fmt.Print("Finding MapleStory window... ")
-	windowRect, err := window.GetMaplestoryWindow()
+	var handle *window.WindowHandle
+	var err error
+	if cfg.WaitForWindow > 0 {
+		fmt.Printf("(waiting up to %s) ", cfg.WaitForWindow)
+		handle, err = window.WaitForMaplestoryHandle(cfg.WaitForWindow, windowPollInterval, func() bool {
+			_, requested := cfg.Stop.Requested()
+			return requested
+		})
+	} else {
+		handle, err = window.GetMaplestoryWindowHandle()
+	}
+	if errors.Is(err, window.ErrMinimized) && cfg.AutoRestore {
+		if _, err = window.RestoreIfMinimized(); err == nil {
+			cfg.clock().Sleep(blankRetryDelay)
+			handle, err = window.GetMaplestoryWindowHandle()
+		}
+	}
+	if errors.Is(err, window.ErrCancelled) {
+		reason, _ := cfg.Stop.Requested()
+		fmt.Printf("\n🛑 %s - stopping before the game window was found\n", reason)
+		return nil, false
+	}
+	if errors.Is(err, window.ErrMinimized) {
+		fmt.Printf("❌ Failed: %v\n", err)
+		fmt.Println("Restore MapleStory from the taskbar, or run with --auto-restore.")
+		return nil, false
+	}
 	if err != nil {
 		fmt.Printf("❌ Failed: %v\n", err)
 		fmt.Println("Make sure MapleStory is running and visible.")
-		return
+		return nil, false
 	}
 	fmt.Println("✅ Found!")
+	applyAutoDPIScale(cfg)
+	windowRect := &handle.Rect
 
 	// Screen region for flame stats (using global constants)
-	fmt.Printf("Monitoring region %dx%d at (%d,%d)\n", CAPTURE_WIDTH, CAPTURE_HEIGHT, CAPTURE_X, CAPTURE_Y)
-	fmt.Printf("Reroll click will be at offset (%d,%d) from window\n", CLICK_OFFSET_X, CLICK_OFFSET_Y)
-	fmt.Printf("Absolute click position will be around (%d,%d)\n", 
-		int(windowRect.Left)+CLICK_OFFSET_X, int(windowRect.Top)+CLICK_OFFSET_Y)
-	fmt.Println("Starting auto-reroll... Press Ctrl+F1 to stop gracefully, or Ctrl+C to force quit")
-	fmt.Println()
-
-	attemptCount := 0
-	var lastThreeTexts [3]string  // Store last 3 OCR results to detect stuck rerolls
-	textIndex := 0
-
-	for {
-		attemptCount++
-		fmt.Printf("=== Attempt #%d ===\n", attemptCount)
-
-		// Check for Ctrl+F1 to stop gracefully
-		if CheckStopKey() {
-			fmt.Println("\n🛑 Ctrl+F1 pressed - stopping gracefully...")
-			break
-		}
-
-		// Capture screenshot
-		fmt.Print("Capturing... ")
-		img, err := screenshot.CaptureScreenRegion(windowRect, CAPTURE_X, CAPTURE_Y, CAPTURE_WIDTH, CAPTURE_HEIGHT)
-		if err != nil {
-			fmt.Printf("❌ Screenshot failed: %v\n", err)
-			continue
-		}
-
-		// Save for debugging (max 1 screenshot, always overwrites)
-		filename, err := screenshot.SaveDebugImage(img, 1)
-		if err != nil {
-			fmt.Printf("❌ Save failed: %v\n", err)
-			continue
-		}
-		fmt.Printf("✅ Saved: %s (latest)\n", filename)
-
-		// Apply OCR
-		fmt.Print("OCR... ")
-		text, err := ocr.ExtractText(filename)
-		if err != nil {
-			fmt.Printf("❌ OCR failed: %v\n", err)
-			time.Sleep(1 * time.Second)
-			continue
-		}
-		fmt.Println("✅ Done")
-
-		// Store this text result in our history for stuck detection
-		lastThreeTexts[textIndex] = strings.TrimSpace(text)
-		textIndex = (textIndex + 1) % 3
-
-		// Check if stats are stuck (same for 3 consecutive attempts)
-		if attemptCount >= 3 {
-			if lastThreeTexts[0] == lastThreeTexts[1] && lastThreeTexts[1] == lastThreeTexts[2] && lastThreeTexts[0] != "" {
-				fmt.Printf("\n⚠️ STUCK DETECTED: Stats haven't changed for 3 consecutive attempts!\n")
-				fmt.Printf("Last OCR result: %s\n", lastThreeTexts[0])
-				fmt.Println("🛑 Reroll mechanism may not be working - stopping script...")
-				break
-			}
-		}
-
-		// Check for main stat occurrences
-		mainStatCount := countMainStatLines(text, MAIN_STAT)
-		fmt.Printf("Text extracted:\n%s\n", text)
-		fmt.Printf("%s + All Stats lines found: %d\n", MAIN_STAT, mainStatCount)
-
-		// Check if we should stop (2+ main stat lines)
-		if mainStatCount >= 2 {
-			fmt.Printf("\n🎉 SUCCESS! Found %d lines with %s!\n", mainStatCount, MAIN_STAT)
-			fmt.Println("Stopping reroll - good stats achieved!")
-			break
+	region := statRegion(windowRect)
+	offsetX, offsetY := clickOffset(windowRect)
+	fmt.Printf("Monitoring region %dx%d at (%d,%d)\n", region.Width, region.Height, region.X, region.Y)
+	fmt.Printf("Reroll click will be at offset (%d,%d) from window\n", offsetX, offsetY)
+	clickX, clickY, err := window.AbsoluteClickPos(windowRect, offsetX, offsetY)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		fmt.Println("Check the reroll click offsets against your MapleStory window size.")
+		return nil, false
+	}
+	fmt.Printf("Absolute click position will be around (%d,%d)\n", clickX, clickY)
+	if cfg.HasConfirmClick() {
+		if _, _, err := window.AbsoluteClickPos(windowRect, cfg.ConfirmX, cfg.ConfirmY); err != nil {
+			fmt.Printf("❌ Error: confirm %v\n", err)
+			return nil, false
 		}
+	}
+	fmt.Printf("Starting auto-reroll... Press %s or Ctrl+C to stop gracefully\n", automation.StopKeyNames())
+	fmt.Println()
 
-		// Not good enough, click to reroll
-		fmt.Println("❌ Not enough main stat lines, rerolling...")
-		triggerReroll(windowRect)
+	return handle, true
+}
 
-		// Wait a moment before next attempt
-		time.Sleep(2 * time.Second)
+// applyAutoDPIScale sets the click scale from the game window's DPI for
+// --dpi-scale=auto. It must run after the window is found.
+func applyAutoDPIScale(cfg *Config) {
+	if !cfg.AutoDPIScale {
+		return
+	}
+	dpi, err := window.WindowDPI()
+	if err != nil {
+		fmt.Printf("⚠️ Could not read the window DPI, clicks are not scaled: %v\n", err)
+		return
 	}
+	automation.SetCursorScale(window.DPIScale(dpi))
+	fmt.Printf("🖥️ Window DPI %d, scaling clicks by %.2f\n", dpi, window.DPIScale(dpi))
 }
 
 // runWeaponMode runs the weapon flame analysis 
-func runWeaponMode(weaponTypeStr string) {
+func runWeaponMode(weaponTypeStr string, cfg *Config) {
 	fmt.Println("⚔️  WEAPON MODE")
 
 	if weaponTypeStr == "" {
@@ -338,260 +1093,357 @@ func runWeaponMode(weaponTypeStr string) {
 	}
 
 	fmt.Printf("Target weapon type: %s\n", weaponType)
-	fmt.Println("Will stop when 2+ lines contain target type + BOSS DMG + IGN DEF")
+	fmt.Printf("Will stop when %d+ lines contain target type + BOSS DMG + IGN DEF%s\n", cfg.RequiredLines, lineValueNote(cfg))
 	fmt.Println("(BOSS MONSTER DAMAGE and IGNORE DEFENSE are always desirable)")
 	fmt.Println()
 
-	// Find MapleStory window
-	fmt.Print("Finding MapleStory window... ")
-	windowRect, err := window.GetMaplestoryWindow()
-	if err != nil {
-		fmt.Printf("❌ Failed: %v\n", err)
-		fmt.Println("Make sure MapleStory is running and visible.")
+	handle, ok := prepareRerollWindow(cfg)
+	if !ok {
 		return
 	}
-	fmt.Println("✅ Found!")
 
-	// Screen region for flame stats (using global constants)
-	fmt.Printf("Monitoring region %dx%d at (%d,%d)\n", CAPTURE_WIDTH, CAPTURE_HEIGHT, CAPTURE_X, CAPTURE_Y)
-	fmt.Printf("Reroll click will be at offset (%d,%d) from window\n", CLICK_OFFSET_X, CLICK_OFFSET_Y)
-	fmt.Println("Starting auto-reroll... Press Ctrl+F1 to stop gracefully")
-	fmt.Println()
+	runRerollLoop(handle, rerollMode{
+		Name: "weapon",
+		Evaluate: func(text string) (int, bool) {
+			weaponStatCount := countWeaponStatLines(text, weaponType, cfg.MinLineValue)
+			return weaponStatCount, enoughLines(weaponStatCount, cfg)
+		},
+		Describe: func(score int) string {
+			return fmt.Sprintf("Weapon stats (%s + BOSS DMG + IGN DEF) found: %d", weaponType, score)
+		},
+		SuccessMessage: func(score int) string {
+			return fmt.Sprintf("Found %d weapon stat lines!", score)
+		},
+		RetryMessage: "❌ Not enough weapon stat lines, rerolling...",
+		ConfigHint:   "check your --type",
+	}, cfg)
+}
 
-	attemptCount := 0
-	var lastThreeTexts [3]string
-	textIndex := 0
+// flameTextKeywords are stat names that appear in every real flame stat box
+var flameTextKeywords = []string{
+	"STR", "DEX", "INT", "LUK", "ALL STAT", "MAX HP", "MAX MP",
+	"ATT", "BOSS", "IGNORE", "DEFENSE", "DAMAGE", "SPEED", "JUMP",
+}
 
-	for {
-		attemptCount++
-		fmt.Printf("=== Attempt #%d ===\n", attemptCount)
+// flameParseSampleLen caps how much unrecognized text a flameTextError quotes
+const flameParseSampleLen = 60
 
-		// Check for Ctrl+F1 to stop gracefully
-		if CheckStopKey() {
-			fmt.Println("\n🛑 Ctrl+F1 pressed - stopping gracefully...")
-			break
-		}
+// flameTextError explains why OCR text was not accepted as flame stats, so an
+// empty read (nothing captured) can be told apart from unrelated text (a
+// drifted region)
+type flameTextError struct {
+	Lines  int    // Non-empty lines read
+	Sample string // Start of the text, for the log
+}
 
-		// Capture screenshot
-		fmt.Print("Capturing... ")
-		img, err := screenshot.CaptureScreenRegion(windowRect, CAPTURE_X, CAPTURE_Y, CAPTURE_WIDTH, CAPTURE_HEIGHT)
-		if err != nil {
-			fmt.Printf("❌ Screenshot failed: %v\n", err)
-			continue
-		}
+func (e *flameTextError) Error() string {
+	if e.Lines == 0 {
+		return "OCR returned no text - the capture may be blank or covered"
+	}
+	return fmt.Sprintf("no stat names in %d line(s) of OCR text: %q", e.Lines, e.Sample)
+}
 
-		// Save for debugging (max 1 screenshot, always overwrites)
-		filename, err := screenshot.SaveDebugImage(img, 1)
-		if err != nil {
-			fmt.Printf("❌ Save failed: %v\n", err)
-			continue
+// checkFlameText returns a *flameTextError unless the OCR text looks like it
+// came from the flame stat box, rather than an unrelated panel picked up by
+// a drifted region
+func checkFlameText(text string) error {
+	upperText := strings.ToUpper(text)
+	for _, keyword := range flameTextKeywords {
+		if strings.Contains(upperText, keyword) {
+			return nil
 		}
-		fmt.Printf("✅ Saved: %s (latest)\n", filename)
+	}
 
-		// Apply OCR
-		fmt.Print("OCR... ")
-		text, err := ocr.ExtractText(filename)
-		if err != nil {
-			fmt.Printf("❌ OCR failed: %v\n", err)
-			time.Sleep(1 * time.Second)
-			continue
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
 		}
-		fmt.Println("✅ Done")
+	}
+	sample := []rune(strings.Join(lines, " | "))
+	if len(sample) > flameParseSampleLen {
+		sample = append(sample[:flameParseSampleLen], []rune("...")...)
+	}
+	return &flameTextError{Lines: len(lines), Sample: string(sample)}
+}
 
-		// Store for stuck detection
-		lastThreeTexts[textIndex] = strings.TrimSpace(text)
-		textIndex = (textIndex + 1) % 3
+// validateFlameText reports whether OCR text looks like flame stats
+func validateFlameText(text string) bool {
+	return checkFlameText(text) == nil
+}
 
-		// Check if stuck
-		if attemptCount >= 3 {
-			if lastThreeTexts[0] == lastThreeTexts[1] && lastThreeTexts[1] == lastThreeTexts[2] && lastThreeTexts[0] != "" {
-				fmt.Printf("\n⚠️ STUCK DETECTED: Stats haven't changed for 3 consecutive attempts!\n")
-				fmt.Printf("Last OCR result: %s\n", lastThreeTexts[0])
-				fmt.Println("🛑 Reroll mechanism may not be working - stopping script...")
-				break
-			}
-		}
+// countMainStatLines counts how many lines contain the main stat or All Stats,
+// only counting lines with a value of at least minValue when it is above 0
+func countMainStatLines(text string, mainStat MainStat, minValue int) int {
+	return countLinesAtLeast(text, func(line string) bool {
+		return isMainStatLine(line, mainStat)
+	}, minValue)
+}
 
-		// Check for weapon stat occurrences
-		weaponStatCount := countWeaponStatLines(text, weaponType)
-		fmt.Printf("Text extracted:\n%s\n", text)
-		fmt.Printf("Weapon stats (%s + BOSS DMG + IGN DEF) found: %d\n", weaponType, weaponStatCount)
+// isMainStatLine reports whether a line contains the main stat or All Stats
+func isMainStatLine(line string, mainStat MainStat) bool {
+	upperLine := strings.ToUpper(line)
 
-		// Check if we should stop (2+ weapon stat lines)
-		if weaponStatCount >= 2 {
-			fmt.Printf("\n🎉 SUCCESS! Found %d weapon stat lines!\n", weaponStatCount)
-			fmt.Println("Stopping reroll - good stats achieved!")
-			break
-		}
+	// Check if line contains the main stat (case insensitive)
+	if strings.Contains(upperLine, strings.ToUpper(mainStat.String())) {
+		return true
+	}
 
-		// Not good enough, click to reroll
-		fmt.Println("❌ Not enough weapon stat lines, rerolling...")
-		triggerReroll(windowRect)
+	// All Stats also counts as main stat since it boosts all stats
+	return isAllStatLine(line)
+}
 
-		// Wait a moment before next attempt
-		time.Sleep(2 * time.Second)
+// primeLineIsMainStat reports whether the first stat line (the prime line)
+// contains the main stat or All Stats
+func primeLineIsMainStat(text string, mainStat MainStat) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !validateFlameText(line) {
+			continue
+		}
+		return isMainStatLine(line, mainStat)
 	}
+	return false
 }
 
-// countMainStatLines counts how many lines contain the main stat or All Stats
-func countMainStatLines(text string, mainStat MainStat) int {
-	if text == "" {
-		return 0
-	}
+// isAllStatLine reports whether a line is an All Stats line
+func isAllStatLine(line string) bool {
+	upperLine := strings.ToUpper(line)
+	return strings.Contains(upperLine, "ALL STAT") || strings.Contains(upperLine, "ALLSTAT")
+}
 
-	lines := strings.Split(text, "\n")
-	count := 0
+// sumMainStatValues adds up the main stat values and All Stats percentages
+// read from the text. Flat All Stats lines ("All Stats: +10", on lower-tier
+// flames) raise the main stat directly, so they count toward the main stat.
+func sumMainStatValues(text string, mainStat MainStat) (mainStatTotal, allStatPercent int) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !isMainStatLine(line, mainStat) {
+			continue
+		}
+
+		value, percent, ok := ocr.ExtractStatValue(line)
+		if !ok {
+			continue
+		}
+		if isAllStatLine(line) && percent {
+			allStatPercent += value
+		} else {
+			mainStatTotal += value
+		}
+	}
+	return mainStatTotal, allStatPercent
+}
 
-	for _, line := range lines {
+// bestMainStatLine returns the highest value on a single main stat line,
+// not counting All Stats lines, or 0 if there is none
+func bestMainStatLine(text string, mainStat MainStat) int {
+	best := 0
+	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || isAllStatLine(line) || !isMainStatLine(line, mainStat) {
 			continue
 		}
+		if value, percent, ok := ocr.ExtractStatValue(line); ok && !percent && value > best {
+			best = value
+		}
+	}
+	return best
+}
+
+// statSum combines main stat and All Stats % into one number, counting each
+// All Stats percent as allStatWeight main stat
+func statSum(mainStatTotal, allStatPercent int, allStatWeight float64) int {
+	return mainStatTotal + int(float64(allStatPercent)*allStatWeight)
+}
+
+// countWeaponStatLines counts weapon-relevant stats (ATT/MATT + BOSS DMG + IGN DEF),
+// only counting lines with a value of at least minValue when it is above 0
+func countWeaponStatLines(text, weaponType string, minValue int) int {
+	return countLinesAtLeast(text, func(line string) bool {
+		return isWeaponStatLine(line, weaponType)
+	}, minValue)
+}
 
-		upperLine := strings.ToUpper(line)
-		
-		// Check if line contains the main stat (case insensitive)
-		if strings.Contains(upperLine, strings.ToUpper(mainStat.String())) {
-			count++
-		} else if strings.Contains(upperLine, "ALL STATS") || 
-				  strings.Contains(upperLine, "ALL STAT") ||
-				  strings.Contains(upperLine, "ALLSTATS") ||
-				  strings.Contains(upperLine, "ALLSTAT") {
-			// All Stats also counts as main stat since it boosts all stats
-			count++
+// isWeaponStatLine reports whether a line is the weapon's attack type, boss
+// damage or ignore defense
+func isWeaponStatLine(line, weaponType string) bool {
+	upperLine := strings.ToUpper(line)
+
+	// Check for target weapon type (ATT or MATT) - more precise matching
+	if weaponType == "ATT" {
+		// Look for "ATT:" or "ATT " or "ATT%" to avoid matching words like "ATTACK"
+		if (strings.Contains(upperLine, "ATT:") ||
+			strings.Contains(upperLine, "ATT ") ||
+			strings.Contains(upperLine, "ATT%")) &&
+			!strings.Contains(upperLine, "MATT") {
+			return true
+		}
+	} else if weaponType == "MATT" {
+		// Look for "MATT:" or "MATT " or "MATT%"
+		if strings.Contains(upperLine, "MATT:") ||
+			strings.Contains(upperLine, "MATT ") ||
+			strings.Contains(upperLine, "MATT%") {
+			return true
 		}
 	}
 
-	return count
+	// Boss Monster Damage is always desirable
+	if strings.Contains(upperLine, "BOSS") && strings.Contains(upperLine, "DAMAGE") {
+		return true
+	}
+
+	// Ignore Defense is always desirable (like All Stats for weapons)
+	return isIgnoreDefenseLine(line)
+}
+
+// isIgnoreDefenseLine reports whether a line is Ignore Defense, in its full
+// or abbreviated ("IGN DEF") form
+func isIgnoreDefenseLine(line string) bool {
+	upperLine := strings.ToUpper(line)
+	return (strings.Contains(upperLine, "IGNORE") && strings.Contains(upperLine, "DEFENSE")) ||
+		(strings.Contains(upperLine, "IGN") && strings.Contains(upperLine, "DEF"))
 }
 
-// countWeaponStatLines counts weapon-relevant stats (ATT/MATT + BOSS DMG + IGN DEF)
-func countWeaponStatLines(text, weaponType string) int {
-	if text == "" {
-		return 0
+// lineValueNote describes the --min-line-value requirement for start-up messages
+func lineValueNote(cfg *Config) string {
+	if cfg.MinLineValue <= 0 {
+		return ""
 	}
+	return fmt.Sprintf(", each with a value of %d or more", cfg.MinLineValue)
+}
 
-	lines := strings.Split(text, "\n")
-	count := 0
+// defaultRequiredLines is the --max-lines default: two matching lines
+const defaultRequiredLines = 2
+
+// enoughLines is the line-count stop rule: count matching lines (already
+// filtered by --min-line-value) meet --max-lines
+func enoughLines(count int, cfg *Config) bool {
+	return count >= cfg.RequiredLines
+}
 
-	for _, line := range lines {
+// countLinesAtLeast counts the lines that match and carry a value of at least
+// minValue. With a minValue of 0 every matching line counts, value or not.
+func countLinesAtLeast(text string, matches func(line string) bool, minValue int) int {
+	count := 0
+	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || !matches(line) {
 			continue
 		}
-
-		upperLine := strings.ToUpper(line)
-		
-		// Check for target weapon type (ATT or MATT) - more precise matching
-		if weaponType == "ATT" {
-			// Look for "ATT:" or "ATT " or "ATT%" to avoid matching words like "ATTACK"
-			if (strings.Contains(upperLine, "ATT:") || 
-				strings.Contains(upperLine, "ATT ") || 
-				strings.Contains(upperLine, "ATT%")) && 
-				!strings.Contains(upperLine, "MATT") {
-				count++
+		if minValue > 0 {
+			if value, _, ok := ocr.ExtractStatValue(line); !ok || value < minValue {
+				continue
 			}
-		} else if weaponType == "MATT" {
-			// Look for "MATT:" or "MATT " or "MATT%"
-			if strings.Contains(upperLine, "MATT:") || 
-			   strings.Contains(upperLine, "MATT ") || 
-			   strings.Contains(upperLine, "MATT%") {
-				count++
-			}
-		}
-		
-		// Check for boss damage (always desirable)
-		if strings.Contains(upperLine, "BOSS") && strings.Contains(upperLine, "DAMAGE") {
-			// Boss Monster Damage is always desirable
-			count++
-		}
-		
-		// Check for ignore defense (always desirable)
-		if strings.Contains(upperLine, "IGNORE") && strings.Contains(upperLine, "DEFENSE") {
-			// Ignore Defense is always desirable (like All Stats for weapons)
-			count++
-		} else if strings.Contains(upperLine, "IGN") && strings.Contains(upperLine, "DEF") {
-			// Alternative format for Ignore Defense
-			count++
 		}
+		count++
 	}
-
 	return count
 }
 
+// clickDebugSize is the side of the square saved around the click by --debug-click
+const clickDebugSize = 50
+
+// saveClickDebug saves the area around the reroll click to temp/click_debug_flame_<attempt>.png
+func saveClickDebug(handle *window.WindowHandle, attempt int) {
+	fmt.Print("📷 Click debug... ")
+	offsetX, offsetY := clickOffset(handle.Bounds())
+	debugImg, err := screenshot.CaptureHandleRegion(handle, screenshot.CenteredRegion(offsetX, offsetY, clickDebugSize))
+	if err != nil {
+		fmt.Printf("⚠️ Debug screenshot failed: %v ", err)
+		return
+	}
+	debugFilename, err := screenshot.SaveDebugImageWithPrefix(debugImg, "click_debug", attempt)
+	if err != nil {
+		fmt.Printf("⚠️ Debug save failed: %v ", err)
+		return
+	}
+	fmt.Printf("✅ Saved %s ", debugFilename)
+}
+
 // triggerReroll clicks on a specific area and presses Enter twice to reroll
-func triggerReroll(windowRect *window.WindowRect) {
+func triggerReroll(handle *window.WindowHandle, cfg *Config, attempt int) {
 	fmt.Print("Triggering reroll... ")
+	windowRect := &handle.Rect
 
 	// Calculate absolute screen coordinates using global constants
-	clickX := int(windowRect.Left) + CLICK_OFFSET_X
-	clickY := int(windowRect.Top) + CLICK_OFFSET_Y
-
-	fmt.Printf("(Click at %d,%d) ", clickX, clickY)
-
-	// Activate MapleStory window first
-	_, err := window.FindAndActivateMaplestory()
+	offsetX, offsetY := clickOffset(windowRect)
+	clickX, clickY, err := window.AbsoluteClickPos(windowRect, offsetX, offsetY)
 	if err != nil {
-		fmt.Printf("❌ Could not activate MapleStory: %v\n", err)
+		fmt.Printf("❌ %v\n", err)
 		return
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	fmt.Printf("(Click at %d,%d) ", clickX, clickY)
+
+	// Activate MapleStory window first, unless the input is posted to it
+	if !cfg.Background {
+		if err := handle.Activate(); err != nil {
+			fmt.Printf("❌ Could not activate MapleStory: %v\n", err)
+			return
+		}
+
+		// Clicking before focus switches sends the click to the previous window
+		if !window.WaitForForeground(handle.HWND, focusTimeout) {
+			fmt.Printf("❌ MapleStory did not take focus within %v - skipping this click\n", focusTimeout)
+			return
+		}
+
+		cfg.clock().Sleep(cfg.ActivateDelay)
+	}
 
-	// // Debug: Capture 20x20 pixel area around click position for debugging
-	// fmt.Print("📷 Debug screenshot... ")
-	// debugImg, err := screenshot.CaptureScreenRegion(windowRect, 
-	// 	clickOffsetX-10, clickOffsetY-10, 50, 50)
-	// if err != nil {
-	// 	fmt.Printf("⚠️ Debug screenshot failed: %v ", err)
-	// } else {
-	// 	// debugFilename, err := screenshot.SaveDebugImageWithPrefix(debugImg, "click_debug", 1)
-	// 	if err != nil {
-	// 		fmt.Printf("⚠️ Debug save failed: %v ", err)
-	// 	} else {
-	// 		fmt.Printf("✅ Saved click debug: %s ", debugFilename)
-	// 	}
-	// }
+	// Show what is under the cursor, for "it clicks the wrong spot" reports
+	if cfg.DebugClick {
+		saveClickDebug(handle, attempt)
+	}
 
-	// Move cursor to click position
-	ret, _, _ := procSetCursorPos.Call(uintptr(clickX), uintptr(clickY))
-	if ret == 0 {
-		fmt.Printf("❌ Failed to set cursor position\n")
+	// Move cursor to click position and click
+	if err := clickReroll(cfg, handle.HWND, clickX, clickY); err != nil {
+		fmt.Printf("❌ %v\n", err)
 		return
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	fmt.Print("✅ Clicked! ")
 
-	// Perform mouse click (left button down and up)
-	procMouseEvent.Call(
-		MOUSEEVENTF_LEFTDOWN,
-		0, 0, 0, 0,
-	)
-	time.Sleep(50 * time.Millisecond)
+	cfg.clock().Sleep(cfg.ClickSettle.Duration()) // Wait for click to register
 
-	procMouseEvent.Call(
-		MOUSEEVENTF_LEFTUP,
-		0, 0, 0, 0,
-	)
+	// Some UIs confirm with a button rather than Enter
+	if cfg.HasConfirmClick() {
+		confirmX, confirmY, err := window.AbsoluteClickPos(windowRect, cfg.ConfirmX, cfg.ConfirmY)
+		if err != nil {
+			fmt.Printf("❌ Confirm click: %v\n", err)
+			return
+		}
+		fmt.Printf("Confirm (%d,%d)... ", confirmX, confirmY)
+		if err := clickReroll(cfg, handle.HWND, confirmX, confirmY); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		fmt.Println("✅ Complete!")
+		return
+	}
 
-	fmt.Print("✅ Clicked! ")
+	// Answer the confirmation dialogs as they appear when a region is configured
+	if !cfg.ConfirmRegion.IsZero() {
+		confirmDialogs(handle, cfg)
+		fmt.Println("✅ Complete!")
+		return
+	}
 
 	// Press Enter twice
-	time.Sleep(200 * time.Millisecond) // Wait for click to register
-	
 	fmt.Print("Enter1... ")
-	PressKey(VK_RETURN)
+	pressRerollKey(cfg, handle.HWND, automation.VK_RETURN)
 	
-	time.Sleep(100 * time.Millisecond)
+	cfg.clock().Sleep(100 * time.Millisecond)
 	
 	fmt.Print("Enter2... ")
-	PressKey(VK_RETURN)
+	pressRerollKey(cfg, handle.HWND, automation.VK_RETURN)
 
 	fmt.Println("✅ Complete!")
 }
 
-// pressSpacebar uses the working keybd_event method from git history
-func pressSpacebar() {
+// pressSpacebar activates the window and presses Spacebar via SendInput
+func pressSpacebar(clock Clock) {
 	fmt.Print("Pressing Spacebar... ")
 
 	// First, ensure MapleStory window is active
@@ -602,16 +1454,16 @@ func pressSpacebar() {
 	}
 
 	// Wait for window to be focused
-	time.Sleep(100 * time.Millisecond)
+	clock.Sleep(100 * time.Millisecond)
 
-	// Use the working PressKey method from git history
-	PressKey(VK_SPACE)
+	// Press Spacebar via SendInput
+	automation.PressKey(automation.VK_SPACE)
 
 	fmt.Println("✅")
 }
 
-// pressEnter uses the working keybd_event method from git history
-func pressEnter() {
+// pressEnter activates the window and presses Enter via SendInput
+func pressEnter(clock Clock) {
 	fmt.Print("Pressing Enter... ")
 
 	// First, ensure MapleStory window is active
@@ -622,39 +1474,10 @@ func pressEnter() {
 	}
 
 	// Wait for window to be focused
-	time.Sleep(100 * time.Millisecond)
+	clock.Sleep(100 * time.Millisecond)
 
-	// Use the working PressKey method from git history
-	PressKey(VK_RETURN)
+	// Press Enter via SendInput
+	automation.PressKey(automation.VK_RETURN)
 
 	fmt.Println("✅")
 }
-
-// PressKey simulates a key press using the working method from git history
-func PressKey(keyCode int) {
-	// Key down
-	procKeyboardEvent.Call(
-		uintptr(keyCode),
-		0,
-		0,
-		0,
-	)
-	time.Sleep(50 * time.Millisecond)
-
-	// Key up
-	procKeyboardEvent.Call(
-		uintptr(keyCode),
-		0,
-		2, // KEYEVENTF_KEYUP
-		0,
-	)
-}
-
-// CheckStopKey checks if the stop key combination (Ctrl+F1) is pressed
-func CheckStopKey() bool {
-	ctrlState, _, _ := procGetAsyncKeyState.Call(uintptr(VK_CONTROL))
-	f1State, _, _ := procGetAsyncKeyState.Call(uintptr(VK_F1))
-	
-	// Check if Ctrl+F1 is pressed
-	return ctrlState&0x8000 != 0 && f1State&0x8000 != 0
-}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maple_flame/internal/automation"
+	"maple_flame/internal/screenshot"
+)
+
+func TestLineCountRuleMixedValues(t *testing.T) {
+	armor := "STR +15%\nAll Stats +6%\nSTR +9%\nDEX +12%\n"
+	weapon := "ATT +30\nBoss Damage +8%\nIGN DEF +3%\nSTR +12\n"
+
+	tests := []struct {
+		name     string
+		count    func(minValue int) int
+		minValue int
+		maxLines int
+		want     int
+		wantStop bool
+	}{
+		{"armor any value", func(v int) int { return countMainStatLines(armor, STR, v) }, 0, 2, 3, true},
+		{"armor two lines at 9", func(v int) int { return countMainStatLines(armor, STR, v) }, 9, 3, 2, false},
+		{"armor one line at 15", func(v int) int { return countMainStatLines(armor, STR, v) }, 15, 2, 1, false},
+		{"armor value right at the minimum", func(v int) int { return countMainStatLines(armor, STR, v) }, 6, 3, 3, true},
+		{"weapon any value", func(v int) int { return countWeaponStatLines(weapon, "ATT", v) }, 0, 3, 3, true},
+		{"weapon two lines at 8", func(v int) int { return countWeaponStatLines(weapon, "ATT", v) }, 8, 2, 2, true},
+		{"weapon none at 31", func(v int) int { return countWeaponStatLines(weapon, "ATT", v) }, 31, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := tt.count(tt.minValue)
+			if count != tt.want {
+				t.Errorf("count with --min-line-value=%d = %d, want %d", tt.minValue, count, tt.want)
+			}
+			if got := enoughLines(count, &Config{RequiredLines: tt.maxLines}); got != tt.wantStop {
+				t.Errorf("enoughLines(%d) with --max-lines=%d = %v, want %v", count, tt.maxLines, got, tt.wantStop)
+			}
+		})
+	}
+}
+
+func TestValidateFlameText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"armor flame", "STR +12%\nDEX +9%\nMax HP +3%\n", true},
+		{"weapon flame", "ATT +30\nBoss Damage +8%\n", true},
+		{"lower case", "luk +6%\nspeed +4\n", true},
+		{"single stat line", "All Stats +5%\n", true},
+		{"empty", "", false},
+		{"inventory", "Mesos 1,234,567\nEquip Use Etc Setup Cash\n", false},
+		{"chat box", "[Guild] hello there\nparty quest at 8?\n", false},
+		{"numbers only", "+12%\n+9%\n+3%\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateFlameText(tt.text); got != tt.want {
+				t.Errorf("validateFlameText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTempFile creates name in dir with the given content and modification time
+func writeTempFile(t *testing.T, dir, name, content string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchivePreviousRun(t *testing.T) {
+	dir := t.TempDir()
+	runEnd := time.Date(2024, 3, 5, 14, 30, 15, 0, time.Local)
+	writeTempFile(t, dir, "flame.log", "old log", runEnd)
+	writeTempFile(t, dir, "debug_1.png", "old image", runEnd.Add(-time.Minute))
+	if err := os.MkdirAll(filepath.Join(dir, "archive", "20240101_000000"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	archived, err := archivePreviousRun(dir)
+	if err != nil {
+		t.Fatalf("archivePreviousRun() error = %v", err)
+	}
+	if want := filepath.Join(dir, "archive", "20240305_143015"); archived != want {
+		t.Errorf("archivePreviousRun() = %q, want %q (named after the last write)", archived, want)
+	}
+	for _, name := range []string{"flame.log", "debug_1.png"} {
+		if _, err := os.Stat(filepath.Join(archived, name)); err != nil {
+			t.Errorf("%s not archived: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still in the temp folder", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "archive", "20240101_000000")); err != nil {
+		t.Errorf("earlier archive was moved: %v", err)
+	}
+
+	// Nothing left to archive
+	if archived, err := archivePreviousRun(dir); archived != "" || err != nil {
+		t.Errorf("archivePreviousRun() on a clean folder = %q, %v, want nothing archived", archived, err)
+	}
+}
+
+func TestSetupLoggingKeepTemp(t *testing.T) {
+	tests := []struct {
+		name     string
+		keepTemp bool
+	}{
+		{"overwrite", false},
+		{"archive", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTempFile(t, dir, "flame.log", "previous run", time.Now().Add(-time.Hour))
+
+			_, flush := setupLogging(dir, false, tt.keepTemp, 0, 0)
+			fmt.Println("this run")
+			flush()
+
+			log, err := os.ReadFile(filepath.Join(dir, "flame.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(log), "previous run") || !strings.Contains(string(log), "this run") {
+				t.Errorf("flame.log = %q, want only this run's output", log)
+			}
+
+			archives, _ := filepath.Glob(filepath.Join(dir, "archive", "*", "flame.log"))
+			if tt.keepTemp && len(archives) != 1 {
+				t.Fatalf("found %d archived logs, want 1", len(archives))
+			}
+			if !tt.keepTemp && len(archives) != 0 {
+				t.Fatalf("found %d archived logs without --keep-temp", len(archives))
+			}
+			if tt.keepTemp {
+				if old, _ := os.ReadFile(archives[0]); string(old) != "previous run" {
+					t.Errorf("archived log = %q, want the previous run's", old)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckFlameText(t *testing.T) {
+	long := strings.Repeat("inventory ", 10)
+
+	tests := []struct {
+		name      string
+		text      string
+		wantErr   bool
+		wantLines int
+		wantMsg   string
+	}{
+		{"full", "STR +12%\nDEX +9%\nBoss Damage +6%\n", false, 0, ""},
+		{"partial", "~~ garbled ~~\nLUK +6%\n'.,\n", false, 0, ""},
+		{"empty", "", true, 0, "OCR returned no text - the capture may be blank or covered"},
+		{"whitespace only", "  \n\t\n", true, 0, "OCR returned no text - the capture may be blank or covered"},
+		{"unrelated", "Mesos 1,234\n\nEquip Use\n", true, 2, `no stat names in 2 line(s) of OCR text: "Mesos 1,234 | Equip Use"`},
+		{"long sample is cut", long, true, 1, `no stat names in 1 line(s) of OCR text: "` + long[:flameParseSampleLen] + `..."`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFlameText(tt.text)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("checkFlameText() error = %v, want nil", err)
+				}
+				return
+			}
+			var parseErr *flameTextError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("checkFlameText() error = %v, want a *flameTextError", err)
+			}
+			if parseErr.Lines != tt.wantLines {
+				t.Errorf("Lines = %d, want %d", parseErr.Lines, tt.wantLines)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSessionOutputDir(t *testing.T) {
+	start := time.Date(2024, 6, 1, 9, 5, 7, 0, time.Local)
+	if got := sessionOutputDir("temp", false, start); got != "temp" {
+		t.Errorf("without --session-dir = %q, want temp", got)
+	}
+	if got, want := sessionOutputDir("temp", true, start), filepath.Join("temp", "session_20240601_090507"); got != want {
+		t.Errorf("with --session-dir = %q, want %q", got, want)
+	}
+}
+
+func TestSessionDirArtifacts(t *testing.T) {
+	base := t.TempDir()
+	dir := sessionOutputDir(base, true, time.Now())
+	defer screenshot.SetOutputDir(screenshot.OutputDir())
+	screenshot.SetOutputDir(dir)
+
+	_, flush := setupLogging(dir, false, false, 0, 0)
+	fmt.Println("session run")
+	flush()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var written []string
+	for _, save := range []func() (string, error){
+		func() (string, error) { return screenshot.SaveDebugImage(img, 1) },
+		func() (string, error) { return screenshot.SaveDebugImageWithPrefix(img, "verify", 2) },
+		func() (string, error) { return screenshot.SaveMilestoneImage(img, 10, 3) },
+		func() (string, error) {
+			return screenshot.SaveMontage([]screenshot.MontageFrame{{Attempt: 1, Image: img}})
+		},
+		func() (string, error) {
+			logger, err := automation.NewActionLogger(dir)
+			if err != nil {
+				return "", err
+			}
+			defer logger.Close()
+			return logger.Path(), nil
+		},
+	} {
+		path, err := save()
+		if err != nil {
+			t.Fatalf("saving an artifact: %v", err)
+		}
+		written = append(written, path)
+	}
+
+	for _, path := range append(written, filepath.Join(dir, "flame.log")) {
+		if filepath.Dir(path) != dir {
+			t.Errorf("%s written outside the session folder %s", path, dir)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s missing: %v", path, err)
+		}
+	}
+
+	// Nothing lands next to the session folder
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(dir) {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("base folder holds %v, want only %s", names, filepath.Base(dir))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Metrics is the per-attempt instrumentation carried by attemptResult, so
+// every output (console, stream, summary) reports the same numbers
+type Metrics struct {
+	CaptureMs  int64              `json:"capture_ms"`           // Capture, crop and save
+	OCRMs      int64              `json:"ocr_ms"`               // Tesseract and text clean-up
+	DecideMs   int64              `json:"decide_ms"`            // Scoring and the stop decision
+	Confidence float64            `json:"confidence,omitempty"` // Mean OCR word confidence, when --min-confidence reads it
+	Stats      map[string]float64 `json:"stats,omitempty"`
+}
+
+// String formats the phase timings, e.g. "capture 45ms, OCR 310ms, decide 1ms"
+func (m Metrics) String() string {
+	return fmt.Sprintf("capture %dms, OCR %dms, decide %dms", m.CaptureMs, m.OCRMs, m.DecideMs)
+}
+
+// metricsTotals accumulates phase timings for the session summary
+type metricsTotals struct {
+	count                    int
+	capture, ocr, decideTime int64
+}
+
+// Add records one attempt's timings
+func (t *metricsTotals) Add(m Metrics) {
+	t.count++
+	t.capture += m.CaptureMs
+	t.ocr += m.OCRMs
+	t.decideTime += m.DecideMs
+}
+
+// String formats the average timings, or "" before any attempt was recorded
+func (t *metricsTotals) String() string {
+	if t.count == 0 {
+		return ""
+	}
+	n := int64(t.count)
+	return Metrics{CaptureMs: t.capture / n, OCRMs: t.ocr / n, DecideMs: t.decideTime / n}.String()
+}
+
+// elapsedMs returns the milliseconds from start to now, never negative
+func elapsedMs(start, now time.Time) int64 {
+	if ms := now.Sub(start).Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 0
+}
+
+// normalizeScore maps a raw score onto 0-100 relative to the best score the
+// item can roll, clamping scores above that reference at 100
+func normalizeScore(score, maxScore int) int {
+	if maxScore <= 0 || score <= 0 {
+		return 0
+	}
+	if score >= maxScore {
+		return 100
+	}
+	return score * 100 / maxScore
+}
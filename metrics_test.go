@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestNormalizeScore(t *testing.T) {
+	tests := []struct {
+		name            string
+		score, maxScore int
+		want            int
+	}{
+		{"zero", 0, 200, 0},
+		{"half", 100, 200, 50},
+		{"rounds down", 171, 200, 85},
+		{"just below the max", 199, 200, 99},
+		{"at the max", 200, 200, 100},
+		{"above the max is clamped", 260, 200, 100},
+		{"negative score", -5, 200, 0},
+		{"no reference", 150, 0, 0},
+		{"small reference", 2, 3, 66},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeScore(tt.score, tt.maxScore); got != tt.want {
+				t.Errorf("normalizeScore(%d, %d) = %d, want %d", tt.score, tt.maxScore, got, tt.want)
+			}
+		})
+	}
+}
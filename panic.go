@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// panicMinWordLen is the shortest word the unknown dialog check looks at;
+// shorter ones are mostly numbers and OCR noise from the panel art
+const panicMinWordLen = 4
+
+// panicExpectedWords are the words the reroll flow itself puts on screen:
+// the stat box, the flame window and its confirmation dialogs
+var panicExpectedWords = []string{
+	"STATS", "STAT", "MAGIC", "MONSTER", "DAMAGE", "BOSS", "IGNORE", "DEFENSE",
+	"SPEED", "JUMP", "LEVEL", "REQUIREMENT", "REDUCED", "ATTACK", "POWER",
+	"DROP", "RATE", "MESOS", "OBTAINED", "RECOVERY", "ITEMS", "ITEM", "SKILLS",
+	"REBIRTH", "FLAME", "FLAMES", "ETERNAL", "POWERFUL", "BLACK", "RAINBOW",
+	"BONUS", "REROLL", "RESET", "OPTION", "OPTIONS", "EQUIP", "EQUIPMENT",
+	"CONFIRM", "CANCEL", "APPLY", "REMAINING", "CURRENT", "AFTER", "BEFORE",
+	"CHANGE", "CHANGED", "SELECT", "WILL", "THIS", "WITH", "FROM", "INTO",
+}
+
+// panicAllowlist builds the set of accepted words from the built-in list and
+// any extra words given with --panic-allow
+func panicAllowlist(extra []string) map[string]bool {
+	allow := make(map[string]bool, len(panicExpectedWords)+len(extra))
+	for _, word := range panicExpectedWords {
+		allow[word] = true
+	}
+	for _, word := range extra {
+		if word = strings.ToUpper(strings.TrimSpace(word)); word != "" {
+			allow[word] = true
+		}
+	}
+	return allow
+}
+
+// unknownWords returns the words in text that aren't on the allowlist, in
+// order and without repeats
+func unknownWords(text string, allow map[string]bool) []string {
+	var unknown []string
+	seen := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToUpper(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if len(word) < panicMinWordLen || allow[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		unknown = append(unknown, word)
+	}
+	return unknown
+}
+
+// checkForUnknownDialog reads the panic region and returns any words that
+// don't belong to the reroll flow
+func checkForUnknownDialog(handle *window.WindowHandle, cfg *Config) ([]string, error) {
+	img, err := screenshot.CaptureHandleRegion(handle, cfg.PanicRegion)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "panic", 1)
+	if err != nil {
+		return nil, err
+	}
+	text, err := ocr.ExtractText(filename)
+	if err != nil {
+		return nil, fmt.Errorf("OCR failed: %v", err)
+	}
+	return unknownWords(text, cfg.PanicAllow), nil
+}
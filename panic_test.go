@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnknownWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		extra []string
+		want  []string
+	}{
+		{"flame stat box", "STR +12%\nBoss Monster Damage +8%\nIgnore Enemy Defense +3%\n", nil, []string{"ENEMY"}},
+		{"confirmation dialog", "Use the Black Flame on this item?\nConfirm  Cancel", nil, nil},
+		{"reroll window", "Rebirth Flame\nBonus Potential will be reset", nil, []string{"POTENTIAL"}},
+		{"event popup", "Event Notice: Claim your daily reward!", nil, []string{"EVENT", "NOTICE", "CLAIM", "YOUR", "DAILY", "REWARD"}},
+		{"trade request", "Player wants to trade with you", nil, []string{"PLAYER", "WANTS", "TRADE"}},
+		{"repeats reported once", "Warning warning WARNING", nil, []string{"WARNING"}},
+		{"short words and numbers ignored", "HP +30 MP 12 LUK Att", nil, nil},
+		{"extra allowed words", "Bonus Potential Enemy", []string{" potential", "enemy", ""}, nil},
+		{"empty", "", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unknownWords(tt.text, panicAllowlist(tt.extra))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("unknownWords(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// uiPixelTolerance is the per-channel difference still treated as a match
+const uiPixelTolerance = 24
+
+// uiPixelCheck is a pixel that has a known color while the reroll UI is open
+type uiPixelCheck struct {
+	X, Y  int
+	Color color.RGBA
+}
+
+// parseUIPixelCheck parses an "x,y=RRGGBB" spec
+func parseUIPixelCheck(spec string) (uiPixelCheck, error) {
+	position, hexColor, ok := strings.Cut(spec, "=")
+	if !ok {
+		return uiPixelCheck{}, fmt.Errorf("invalid UI check pixel %q: expected x,y=RRGGBB", spec)
+	}
+
+	var check uiPixelCheck
+	if _, err := fmt.Sscanf(strings.TrimSpace(position), "%d,%d", &check.X, &check.Y); err != nil {
+		return uiPixelCheck{}, fmt.Errorf("invalid UI check pixel %q: bad position %q", spec, position)
+	}
+
+	hexColor = strings.TrimPrefix(strings.TrimSpace(hexColor), "#")
+	value, err := strconv.ParseUint(hexColor, 16, 32)
+	if err != nil || len(hexColor) != 6 {
+		return uiPixelCheck{}, fmt.Errorf("invalid UI check pixel %q: bad color %q", spec, hexColor)
+	}
+	check.Color = color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+
+	return check, nil
+}
+
+// Matches reports whether a sampled color is close enough to the expected one
+func (c uiPixelCheck) Matches(sample color.RGBA) bool {
+	return abs(int(sample.R)-int(c.Color.R)) <= uiPixelTolerance &&
+		abs(int(sample.G)-int(c.Color.G)) <= uiPixelTolerance &&
+		abs(int(sample.B)-int(c.Color.B)) <= uiPixelTolerance
+}
+
+// uiIsOpen samples the check pixel and reports whether the reroll UI appears open
+func uiIsOpen(handle *window.WindowHandle, check uiPixelCheck) (bool, color.RGBA, error) {
+	sample, err := screenshot.SamplePixel(handle, check.X, check.Y)
+	if err != nil {
+		return false, sample, err
+	}
+	return check.Matches(sample), sample, nil
+}
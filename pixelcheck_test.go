@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseUIPixelCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    uiPixelCheck
+		wantErr bool
+	}{
+		{"plain", "120,45=FFAA00", uiPixelCheck{120, 45, color.RGBA{0xFF, 0xAA, 0x00, 255}}, false},
+		{"hash and spaces", " 3, 4 = #1a2b3c", uiPixelCheck{3, 4, color.RGBA{0x1A, 0x2B, 0x3C, 255}}, false},
+		{"missing color", "120,45", uiPixelCheck{}, true},
+		{"bad position", "x,45=FFAA00", uiPixelCheck{}, true},
+		{"short color", "120,45=FFA", uiPixelCheck{}, true},
+		{"not hex", "120,45=GGAA00", uiPixelCheck{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUIPixelCheck(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUIPixelCheck(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseUIPixelCheck(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUIPixelCheckMatches(t *testing.T) {
+	check := uiPixelCheck{Color: color.RGBA{100, 150, 200, 255}}
+
+	tests := []struct {
+		name   string
+		sample color.RGBA
+		want   bool
+	}{
+		{"exact", color.RGBA{100, 150, 200, 255}, true},
+		{"at the tolerance", color.RGBA{100 + uiPixelTolerance, 150 - uiPixelTolerance, 200, 255}, true},
+		{"red just past it", color.RGBA{100 + uiPixelTolerance + 1, 150, 200, 255}, false},
+		{"blue just past it", color.RGBA{100, 150, 200 - uiPixelTolerance - 1, 255}, false},
+		{"UI closed", color.RGBA{0, 0, 0, 255}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check.Matches(tt.sample); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.sample, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// statRead is one read of the stat box
+type statRead struct {
+	Text       string
+	Confidence float64     // Mean word confidence, only measured with --min-confidence
+	Image      *image.RGBA // The image OCR read, after --text-band and --invert=on
+	Filename   string      // Where that image was saved
+}
+
+// statImage turns a cropped capture into the image OCR reads: --text-band
+// tightens it around the text rows and --invert=on flips its polarity
+func statImage(img *image.RGBA, cfg *Config) *image.RGBA {
+	if cfg.TextBand {
+		img = screenshot.CropToTextBand(img, textInkThreshold, textBandDensity, textBandMargin)
+	}
+	if cfg.Invert == invertOn {
+		img = screenshot.InvertImage(img)
+	}
+	return img
+}
+
+// ocrFunc reads the text in a saved image
+type ocrFunc func(imagePath string) (string, error)
+
+// ocrConfidenceFunc reads the text in a saved image along with its mean word
+// confidence (0-100)
+type ocrConfidenceFunc func(imagePath string) (string, float64, error)
+
+// ocrFile runs the configured OCR method on a saved image and applies the
+// aliases. confidence is only measured with --min-confidence, and not for a
+// cfg.OCR replacement unless cfg.OCRConfidence replaces that read too.
+func ocrFile(filename string, cfg *Config) (text string, confidence float64, err error) {
+	switch {
+	case cfg.MinConfidence > 0 && cfg.OCRConfidence != nil:
+		text, confidence, err = cfg.OCRConfidence(filename)
+	case cfg.OCR != nil:
+		text, err = cfg.OCR(filename)
+	case cfg.MinConfidence > 0:
+		text, confidence, err = ocr.ExtractTextWithConfidence(filename)
+	case cfg.SaveEnhanced:
+		text, err = ocr.ExtractFlameText(filename)
+	default:
+		text, err = ocr.ExtractText(filename)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return ocr.NormalizeStatText(text, cfg.StatAliases), confidence, nil
+}
+
+// readStats reads the stats from a capture already cropped by
+// --crop-*, the same way for every read in a session: statImage, the OCR
+// method, the aliases, --invert=auto and --tall-panel. The image is saved as
+// the latest debug_ss image when prefix is empty, and under prefix and n
+// otherwise. An unsure read is returned as is, without the extra reads.
+func readStats(img *image.RGBA, handle *window.WindowHandle, cfg *Config, prefix string, n int) (statRead, error) {
+	read := statRead{Image: statImage(img, cfg)}
+
+	var err error
+	if prefix == "" {
+		read.Filename, err = screenshot.SaveDebugImage(read.Image, 1)
+	} else {
+		read.Filename, err = screenshot.SaveDebugImageWithPrefix(read.Image, prefix, n)
+	}
+	if err != nil {
+		return statRead{}, err
+	}
+
+	read.Text, read.Confidence, err = ocrFile(read.Filename, cfg)
+	if err != nil {
+		return statRead{}, err
+	}
+	if !confidentRead(read.Confidence, cfg.MinConfidence) {
+		return read, nil
+	}
+
+	// A poor read may just be the wrong polarity; try the inverted capture
+	if cfg.Invert == invertAuto && !validateFlameText(read.Text) {
+		if inverted, err := readInverted(read.Image, cfg); err == nil {
+			var used bool
+			if read.Text, used = pickPolarity(read.Text, inverted); used {
+				fmt.Println("🔁 Inverted colors read better, using that")
+			}
+		}
+	}
+
+	// Items with many lines run past the capture; read the part below too
+	if cfg.TallPanel && cfg.Frames == nil {
+		if lower, err := readLowerPanel(handle, cfg); err != nil {
+			fmt.Printf("⚠️ Lower panel read failed: %v\n", err)
+		} else {
+			read.Text = ocr.MergeVertical(read.Text, lower, tallPanelOverlapLines)
+		}
+	}
+
+	return read, nil
+}
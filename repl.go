@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"maple_flame/internal/ocr"
+)
+
+// runRepl reads blocks of OCR text from in (ended by a blank line) and prints
+// how the armor and weapon counters and the drop extractors parse them
+func runRepl(in io.Reader, out io.Writer, mainStatStr, weaponTypeStr string, cfg *Config) {
+	mainStat, err := parseMainStat(mainStatStr)
+	if err != nil {
+		mainStat = STR
+	}
+	weaponType := strings.ToUpper(strings.TrimSpace(weaponTypeStr))
+	if weaponType != "ATT" && weaponType != "MATT" {
+		weaponType = "ATT"
+	}
+
+	fmt.Fprintln(out, "🧪 EXTRACTION REPL")
+	fmt.Fprintf(out, "Main stat: %s, weapon type: %s\n", mainStat, weaponType)
+	fmt.Fprintln(out, "Paste OCR text and finish each block with an empty line (Ctrl+Z/Ctrl+D to quit)")
+
+	scanner := bufio.NewScanner(in)
+	var block []string
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+		if more && strings.TrimSpace(line) != "" {
+			block = append(block, line)
+			continue
+		}
+
+		if len(block) > 0 {
+			printExtraction(out, strings.Join(block, "\n"), mainStat, weaponType, cfg)
+			block = nil
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// printExtraction prints every parser's view of a block of OCR text
+func printExtraction(out io.Writer, text string, mainStat MainStat, weaponType string, cfg *Config) {
+	text = ocr.NormalizeStatText(text, cfg.StatAliases)
+
+	fmt.Fprintln(out, "--- Parsed ---")
+	fmt.Fprintf(out, "Normalized text:\n%s\n", text)
+	fmt.Fprintf(out, "Looks like flame stats: %t\n", validateFlameText(text))
+	fmt.Fprintf(out, "%s + All Stats lines: %d\n", mainStat, countMainStatLines(text, mainStat, cfg.MinLineValue))
+	fmt.Fprintf(out, "Weapon stats (%s + BOSS DMG + IGN DEF): %d\n", weaponType, countWeaponStatLines(text, weaponType, cfg.MinLineValue))
+	fmt.Fprintf(out, "Item Drop Rate: %d%%, Mesos Obtained: %d%%\n", ocr.ExtractItemDropRate(text), ocr.ExtractMesosObtained(text))
+	_, _, keywordLines := ocr.DetectPrimeLines(text, false)
+	_, _, valueLines := ocr.DetectPrimeLines(text, true)
+	fmt.Fprintf(out, "Prime drop/mesos lines: %d by keyword, %d with a value\n", keywordLines, valueLines)
+	fmt.Fprintln(out)
+}
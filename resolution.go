@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// parseResolution parses a "WIDTHxHEIGHT" window size such as "1280x720"
+func parseResolution(spec string) (width, height int, err error) {
+	widthText, heightText, ok := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), "x")
+	width, err1 := strconv.Atoi(widthText)
+	height, err2 := strconv.Atoi(heightText)
+	if !ok || err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid resolution %q: expected WIDTHxHEIGHT, e.g. 1280x720", spec)
+	}
+	return width, height, nil
+}
+
+// statRegion returns the flame stat capture region for the window, scaled
+// from the reference resolution when one is set
+func statRegion(windowRect *window.WindowRect) screenshot.Region {
+	x, y, w, h := window.ScaleToWindow(windowRect, CAPTURE_X, CAPTURE_Y, CAPTURE_WIDTH, CAPTURE_HEIGHT)
+	return screenshot.Region{X: x, Y: y, Width: w, Height: h}
+}
+
+// clickOffset returns the reroll button offset for the window, scaled from
+// the reference resolution when one is set
+func clickOffset(windowRect *window.WindowRect) (x, y int) {
+	x, y, _, _ = window.ScaleToWindow(windowRect, CLICK_OFFSET_X, CLICK_OFFSET_Y, 0, 0)
+	return x, y
+}
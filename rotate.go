@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// rotatingWriter appends to a log file and rolls it over once it reaches
+// maxSize bytes: path becomes path.1, path.1 becomes path.2 and so on, with
+// anything past keep old files deleted. If rolling over fails the failure is
+// noted in the log once and the writer keeps appending to path.
+type rotatingWriter struct {
+	path     string
+	maxSize  int64
+	keep     int
+	file     *os.File
+	size     int64
+	noRotate bool // Set after a failed rotation
+}
+
+// openRotatingWriter opens path for appending, picking up its current size
+func openRotatingWriter(path string, maxSize int64, keep int) (*rotatingWriter, error) {
+	w := &rotatingWriter{path: path, maxSize: maxSize, keep: keep}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open (re)opens the current log file
+func (w *rotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. A write that would cross the size limit rolls
+// the file first, so each file stays under maxSize unless a single write is
+// larger than that. A failed rotation is not returned: the log is the copy
+// target of stdout, and an error there would stop the copy and block every
+// later print once the pipe fills. If the log can't even be reopened the
+// output is dropped.
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	if !w.noRotate && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			w.keepAppending(err)
+		}
+	}
+	if w.file == nil {
+		return len(p), nil
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files up by one and starts a fresh log file. The
+// current file is closed first (Windows can't rename an open file), so on
+// an error w.file is nil unless reopening it worked.
+func (w *rotatingWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return err
+	}
+	if err := w.shift(); err != nil {
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return w.open()
+}
+
+// shift moves path and its old files up by one, deleting the oldest
+func (w *rotatingWriter) shift() error {
+	if w.keep < 1 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	os.Remove(rotatedName(w.path, w.keep))
+	for i := w.keep - 1; i >= 1; i-- {
+		if err := os.Rename(rotatedName(w.path, i), rotatedName(w.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(w.path, rotatedName(w.path, 1))
+}
+
+// keepAppending gives up on rotation after it failed with rotateErr: it makes
+// sure path is open again and notes the failure in it
+func (w *rotatingWriter) keepAppending(rotateErr error) {
+	w.noRotate = true
+	if w.file == nil && w.open() != nil {
+		return
+	}
+	n, _ := fmt.Fprintf(w.file, "⚠️ Log rotation failed, appending to %s from now on: %v\n", w.path, rotateErr)
+	w.size += int64(n)
+}
+
+// Close closes the current log file
+func (w *rotatingWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// rotatedName is the name of the n-th old log file, e.g. flame.log.2
+func rotatedName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
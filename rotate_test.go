@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		maxSize  int64
+		keep     int
+		writes   []string
+		want     []string // flame.log, flame.log.1, ... ("" = must not exist)
+	}{
+		{"under the limit", "", 10, 2, []string{"abc", "def"}, []string{"abcdef", ""}},
+		{"rolls over", "", 10, 2, []string{"12345\n", "abcdef\n", "xyz\n"}, []string{"xyz\n", "abcdef\n", "12345\n"}},
+		{"drops past keep", "", 10, 1, []string{"12345\n", "abcdef\n", "xyz\n"}, []string{"xyz\n", "abcdef\n", ""}},
+		{"keep 0", "", 10, 0, []string{"12345\n", "abcdef\n", "xyz\n"}, []string{"xyz\n", ""}},
+		{"oversized write", "", 4, 2, []string{"123456789", "ab"}, []string{"ab", "123456789", ""}},
+		{"appends to existing", "old\n", 6, 2, []string{"new\n"}, []string{"new\n", "old\n", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "flame.log")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w, err := openRotatingWriter(path, tt.maxSize, tt.keep)
+			if err != nil {
+				t.Fatalf("openRotatingWriter() error: %v", err)
+			}
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+					t.Fatalf("Write(%q) = %d, %v", s, n, err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			for i, want := range tt.want {
+				name := path
+				if i > 0 {
+					name = rotatedName(path, i)
+				}
+				got, err := os.ReadFile(name)
+				if want == "" {
+					if !os.IsNotExist(err) {
+						t.Errorf("%s exists (%q), want it absent", filepath.Base(name), got)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("reading %s: %v", filepath.Base(name), err)
+				} else if string(got) != want {
+					t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRotatingWriterKeepsAppendingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flame.log")
+
+	// A non-empty folder in the way of flame.log.1 can't be removed or renamed over
+	blocker := rotatedName(path, 1)
+	if err := os.MkdirAll(filepath.Join(blocker, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := openRotatingWriter(path, 10, 1)
+	if err != nil {
+		t.Fatalf("openRotatingWriter() error: %v", err)
+	}
+	for _, s := range []string{"12345\n", "abcdef\n", "xyz\n"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	w.Close()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(got)
+	if !strings.HasPrefix(text, "12345\n") || !strings.HasSuffix(text, "abcdef\nxyz\n") {
+		t.Errorf("log = %q, want every write appended", text)
+	}
+	if strings.Count(text, "Log rotation failed") != 1 {
+		t.Errorf("log = %q, want the rotation failure noted once", text)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// regionCandidates lists capture-sized regions covering a width x height
+// window, half a region apart, nearest to the configured (x, y) first so a
+// small drift is found after only a few OCR calls
+func regionCandidates(width, height, regionW, regionH, x, y int) []screenshot.Region {
+	stepX, stepY := regionW/2, regionH/2
+	if stepX < 1 || stepY < 1 {
+		return nil
+	}
+
+	var candidates []screenshot.Region
+	for top := 0; top+regionH <= height; top += stepY {
+		for left := 0; left+regionW <= width; left += stepX {
+			candidates = append(candidates, screenshot.Region{X: left, Y: top, Width: regionW, Height: regionH})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		di := abs(candidates[i].X-x) + abs(candidates[i].Y-y)
+		dj := abs(candidates[j].X-x) + abs(candidates[j].Y-y)
+		return di < dj
+	})
+	return candidates
+}
+
+// findStatRegion reads each candidate in order and returns the first whose
+// text looks like flame stats. read is injectable so the search can run on
+// a saved full-window image.
+func findStatRegion(candidates []screenshot.Region, read func(screenshot.Region) (string, error)) (screenshot.Region, string, bool) {
+	for _, region := range candidates {
+		text, err := read(region)
+		if err != nil {
+			continue
+		}
+		if validateFlameText(text) {
+			return region, text, true
+		}
+	}
+	return screenshot.Region{}, "", false
+}
+
+// readRegionOf OCRs one region of a full-window image, skipping regions that
+// are entirely dark without running tesseract
+func readRegionOf(full *image.RGBA, region screenshot.Region, cfg *Config) (string, error) {
+	bounds := full.Bounds()
+	img := screenshot.Crop(full, screenshot.Margins{
+		Top:    region.Y,
+		Left:   region.X,
+		Bottom: bounds.Dy() - region.Y - region.Height,
+		Right:  bounds.Dx() - region.X - region.Width,
+	})
+	if screenshot.IsBlankRegion(img, blankThreshold) {
+		return "", nil
+	}
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "scan", 1)
+	if err != nil {
+		return "", err
+	}
+	text, err := ocr.ExtractText(filename)
+	if err != nil {
+		return "", err
+	}
+	return ocr.NormalizeStatText(text, cfg.StatAliases), nil
+}
+
+// scanForStatRegion captures the whole window and searches it for the flame
+// stat box, returning where it was found
+func scanForStatRegion(handle *window.WindowHandle, cfg *Config) (screenshot.Region, bool) {
+	windowRect := handle.Bounds()
+	width := int(windowRect.Right - windowRect.Left)
+	height := int(windowRect.Bottom - windowRect.Top)
+
+	full, err := screenshot.CaptureHandleRegion(handle, screenshot.Region{Width: width, Height: height})
+	if err != nil {
+		fmt.Printf("❌ Full window capture failed: %v\n", err)
+		return screenshot.Region{}, false
+	}
+
+	expected := statRegion(windowRect)
+	candidates := regionCandidates(width, height, expected.Width, expected.Height, expected.X, expected.Y)
+	fmt.Printf("🔎 Scanning %d candidate regions for the flame stats...\n", len(candidates))
+
+	region, _, found := findStatRegion(candidates, func(r screenshot.Region) (string, error) {
+		return readRegionOf(full, r, cfg)
+	})
+	return region, found
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"maple_flame/internal/ocr"
+	"maple_flame/internal/screenshot"
+)
+
+// selfTestImage is a real flame stat capture with known text, so the OCR
+// path can be checked without the game running
+//
+//go:embed testdata/selftest_stats.png
+var selfTestImage []byte
+
+// selfTestExpected are the stop expression values the reference image reads
+// as: "DEX +6%", "DEF +120", "Max HP +3%"
+var selfTestExpected = map[string]float64{"DEX": 6, "HP": 3}
+
+// selfTestMismatches compares the values read from text against expected and
+// describes each one that differs, in name order
+func selfTestMismatches(text string, expected map[string]float64) []string {
+	values := stopExprValues(text, 0)
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mismatches []string
+	for _, name := range names {
+		if got := values[name]; got != expected[name] {
+			mismatches = append(mismatches, fmt.Sprintf("%s: read %.0f, expected %.0f", name, got, expected[name]))
+		}
+	}
+	return mismatches
+}
+
+// runSelfTest runs the reference image through the enhancement, OCR and stat
+// extraction path and reports whether the expected stats came out
+func runSelfTest(cfg *Config) bool {
+	fmt.Println("🧪 OCR SELF-TEST")
+
+	// Without tesseract, OCR falls back to simulated text
+	if check := checkTesseract(); !check.OK {
+		fmt.Printf("❌ FAIL: %s\n", check.Detail)
+		return false
+	}
+
+	return selfTestWith(ocr.ExtractFlameText, cfg)
+}
+
+// selfTestWith runs the reference image through extract, the OCR step of
+// the self-test, and reports whether the expected stats came out
+func selfTestWith(extract func(imagePath string) (string, error), cfg *Config) bool {
+	tempDir := screenshot.OutputDir()
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		fmt.Printf("❌ FAIL: %v\n", err)
+		return false
+	}
+	imagePath := filepath.Join(tempDir, "selftest.png")
+	if err := os.WriteFile(imagePath, selfTestImage, 0644); err != nil {
+		fmt.Printf("❌ FAIL: could not write the reference image: %v\n", err)
+		return false
+	}
+	defer os.Remove(imagePath)
+
+	text, err := extract(imagePath)
+	if err != nil {
+		fmt.Printf("❌ FAIL: OCR failed: %v\n", err)
+		return false
+	}
+	text = ocr.NormalizeStatText(text, cfg.StatAliases)
+	fmt.Printf("Text extracted:\n%s\n", strings.TrimSpace(text))
+
+	if mismatches := selfTestMismatches(text, selfTestExpected); len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			fmt.Printf("   ✗ %s\n", mismatch)
+		}
+		fmt.Println("❌ FAIL: OCR did not read the reference stats correctly - check your tesseract install and --tessdata-dir/--tess-config")
+		return false
+	}
+	fmt.Println("✅ PASS: tesseract and the enhancement pipeline read the reference stats correctly")
+	return true
+}
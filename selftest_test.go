@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"maple_flame/internal/screenshot"
+)
+
+func TestSelfTestWith(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  error
+		want bool
+	}{
+		{"correct read", "DEX +6%\nDEF +120\nMax HP +3%\n", nil, true},
+		{"wrong value", "DEX +8%\nDEF +120\nMax HP +3%\n", nil, false},
+		{"missing line", "DEF +120\nMax HP +3%\n", nil, false},
+		{"ocr error", "", errors.New("tesseract crashed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer screenshot.SetOutputDir(screenshot.OutputDir())
+			screenshot.SetOutputDir(t.TempDir())
+
+			extract := func(imagePath string) (string, error) {
+				if _, err := os.Stat(imagePath); err != nil {
+					t.Errorf("reference image not written: %v", err)
+				}
+				return tt.text, tt.err
+			}
+			if got := selfTestWith(extract, &Config{}); got != tt.want {
+				t.Errorf("selfTestWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// sessionSummary aggregates the attempts of one recorded session
+type sessionSummary struct {
+	Path        string
+	Attempts    int
+	Successes   int
+	BestScore   int
+	MeanScore   float64
+	PerAttempt  time.Duration // Wall time between attempts (0 with fewer than two)
+	SuccessRate float64       // Successes / attempts, 0-1
+}
+
+// loadSession reads a --stream JSON-lines file. Blank lines are skipped; a
+// malformed line is an error naming its line number.
+func loadSession(path string) ([]attemptResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []attemptResult
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var result attemptResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// summarizeSession computes the aggregates compared between sessions
+func summarizeSession(path string, results []attemptResult) sessionSummary {
+	summary := sessionSummary{Path: path, Attempts: len(results)}
+	if len(results) == 0 {
+		return summary
+	}
+
+	total := 0
+	for i, result := range results {
+		total += result.Score
+		if i == 0 || result.Score > summary.BestScore {
+			summary.BestScore = result.Score
+		}
+		if result.Success {
+			summary.Successes++
+		}
+	}
+	summary.MeanScore = float64(total) / float64(len(results))
+	summary.SuccessRate = float64(summary.Successes) / float64(len(results))
+
+	if len(results) > 1 {
+		elapsed := results[len(results)-1].Time.Sub(results[0].Time)
+		summary.PerAttempt = elapsed / time.Duration(len(results)-1)
+	}
+	return summary
+}
+
+// runCompareSessions loads two session logs given as "A,B" and prints their
+// aggregates side by side
+func runCompareSessions(spec string) {
+	paths := strings.Split(spec, ",")
+	if len(paths) != 2 || strings.TrimSpace(paths[0]) == "" || strings.TrimSpace(paths[1]) == "" {
+		fmt.Printf("❌ Error: --compare-sessions needs two logs as A.jsonl,B.jsonl, got %q\n", spec)
+		return
+	}
+
+	summaries := make([]sessionSummary, 0, 2)
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		results, err := loadSession(path)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		summaries = append(summaries, summarizeSession(path, results))
+	}
+	a, b := summaries[0], summaries[1]
+
+	fmt.Println("📊 SESSION COMPARISON")
+	fmt.Printf("A: %s\nB: %s\n\n", a.Path, b.Path)
+	fmt.Printf("%-18s %12s %12s\n", "", "A", "B")
+	fmt.Printf("%-18s %12d %12d\n", "Attempts", a.Attempts, b.Attempts)
+	fmt.Printf("%-18s %12d %12d\n", "Best score", a.BestScore, b.BestScore)
+	fmt.Printf("%-18s %12.2f %12.2f\n", "Mean score", a.MeanScore, b.MeanScore)
+	fmt.Printf("%-18s %11.1f%% %11.1f%%\n", "Success rate", a.SuccessRate*100, b.SuccessRate*100)
+	fmt.Printf("%-18s %12s %12s\n", "Time per attempt", a.PerAttempt.Round(time.Millisecond), b.PerAttempt.Round(time.Millisecond))
+}
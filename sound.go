@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"maple_flame/internal/sound"
+)
+
+// outcome is an event the user can get a distinct sound for
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // The stop rule matched
+	outcomeStuck                  // Stopped because the stats stopped changing
+	outcomeError                  // Stopped on a problem that needs attention
+	outcomeBest                   // A new best score during the run
+)
+
+// defaultSounds are beep patterns that can be told apart without looking:
+// rising for success, two long low tones for stuck, one buzz for an error
+// and a short blip for a new best
+var defaultSounds = map[outcome]string{
+	outcomeSuccess: "880:150,1175:150,1568:300",
+	outcomeStuck:   "440:500,0:150,440:500",
+	outcomeError:   "220:900",
+	outcomeBest:    "1320:80",
+}
+
+// tone is one step of a beep pattern; a frequency of 0 is a pause
+type tone struct {
+	Frequency int
+	Duration  time.Duration
+}
+
+// soundSpec is what plays for an outcome: a WAV file or a beep pattern
+type soundSpec struct {
+	WAV   string
+	Tones []tone
+}
+
+// parseSoundSpec parses a WAV file path or a "freq:ms,freq:ms" beep pattern.
+// "off" means no sound and returns nil.
+func parseSoundSpec(spec string) (*soundSpec, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.EqualFold(spec, "off") {
+		return nil, nil
+	}
+	if strings.HasSuffix(strings.ToLower(spec), ".wav") {
+		return &soundSpec{WAV: spec}, nil
+	}
+
+	var tones []tone
+	for _, part := range strings.Split(spec, ",") {
+		freq, ms, ok := strings.Cut(strings.TrimSpace(part), ":")
+		frequency, err1 := strconv.Atoi(freq)
+		millis, err2 := strconv.Atoi(ms)
+		if !ok || err1 != nil || err2 != nil || millis <= 0 || (frequency != 0 && (frequency < 37 || frequency > 32767)) {
+			return nil, fmt.Errorf("invalid sound %q: expected a .wav path or freq:ms steps like 880:150,0:100 (freq 37-32767, 0 = pause)", spec)
+		}
+		tones = append(tones, tone{Frequency: frequency, Duration: time.Duration(millis) * time.Millisecond})
+	}
+	return &soundSpec{Tones: tones}, nil
+}
+
+// soundSet maps each outcome to its sound; outcomes without one stay quiet
+type soundSet map[outcome]*soundSpec
+
+// newSoundSet builds the sounds for a run. With enabled every outcome gets
+// its default unless overridden; otherwise only overridden outcomes sound.
+// It returns nil when nothing would ever play.
+func newSoundSet(enabled bool, overrides map[outcome]string) (soundSet, error) {
+	set := soundSet{}
+	for o, pattern := range defaultSounds {
+		spec := overrides[o]
+		if spec == "" {
+			if !enabled {
+				continue
+			}
+			spec = pattern
+		}
+		parsed, err := parseSoundSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		if parsed != nil {
+			set[o] = parsed
+		}
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// outcomeNames label outcomes in the start-up banner, in outcome order
+var outcomeNames = []string{"success", "stuck", "error", "best"}
+
+// String lists the outcomes that have a sound, e.g. "success, stuck"
+func (s soundSet) String() string {
+	var names []string
+	for o, name := range outcomeNames {
+		if s[outcome(o)] != nil {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Play plays the outcome's sound, if it has one, and waits for it to end
+func (s soundSet) Play(o outcome) {
+	spec := s[o]
+	if spec == nil {
+		return
+	}
+	if spec.WAV != "" {
+		if err := sound.PlayWAV(spec.WAV); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+		return
+	}
+	for _, t := range spec.Tones {
+		if err := sound.Beep(t.Frequency, t.Duration); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+			return
+		}
+	}
+}
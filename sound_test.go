@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSoundSpec(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    *soundSpec
+		wantErr bool
+	}{
+		{"off", " OFF ", nil, false},
+		{"wav file", `C:\sounds\done.WAV`, &soundSpec{WAV: `C:\sounds\done.WAV`}, false},
+		{"pattern with a pause", "880:150, 0:100,440:300", &soundSpec{Tones: []tone{{880, 150 * ms}, {0, 100 * ms}, {440, 300 * ms}}}, false},
+		{"frequency too low", "20:100", nil, true},
+		{"frequency too high", "40000:100", nil, true},
+		{"zero duration", "880:0", nil, true},
+		{"missing duration", "880", nil, true},
+		{"not a number", "high:100", nil, true},
+		{"other file type", "done.mp3", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSoundSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSoundSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSoundSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSoundSet(t *testing.T) {
+	defaults := map[outcome]*soundSpec{}
+	for o, pattern := range defaultSounds {
+		spec, err := parseSoundSpec(pattern)
+		if err != nil {
+			t.Fatalf("default sound for %s: %v", outcomeNames[o], err)
+		}
+		defaults[o] = spec
+	}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		overrides map[outcome]string
+		want      map[outcome]*soundSpec // nil = no sound set at all
+	}{
+		{"off", false, nil, nil},
+		{"defaults", true, nil, defaults},
+		{"override one", true, map[outcome]string{outcomeError: "alarm.wav"}, map[outcome]*soundSpec{
+			outcomeSuccess: defaults[outcomeSuccess],
+			outcomeStuck:   defaults[outcomeStuck],
+			outcomeError:   {WAV: "alarm.wav"},
+			outcomeBest:    defaults[outcomeBest],
+		}},
+		{"silence one", true, map[outcome]string{outcomeBest: "off"}, map[outcome]*soundSpec{
+			outcomeSuccess: defaults[outcomeSuccess],
+			outcomeStuck:   defaults[outcomeStuck],
+			outcomeError:   defaults[outcomeError],
+		}},
+		{"only the overridden outcome without --sound", false, map[outcome]string{outcomeStuck: "440:200"}, map[outcome]*soundSpec{
+			outcomeStuck: {Tones: []tone{{440, 200 * time.Millisecond}}},
+		}},
+		{"everything silenced", true, map[outcome]string{outcomeSuccess: "off", outcomeStuck: "off", outcomeError: "off", outcomeBest: "off"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := newSoundSet(tt.enabled, tt.overrides)
+			if err != nil {
+				t.Fatalf("newSoundSet() error = %v", err)
+			}
+			if tt.want == nil {
+				if set != nil {
+					t.Errorf("newSoundSet() = %v, want nil", set)
+				}
+				return
+			}
+			for _, o := range []outcome{outcomeSuccess, outcomeStuck, outcomeError, outcomeBest} {
+				if !reflect.DeepEqual(set[o], tt.want[o]) {
+					t.Errorf("%s sound = %+v, want %+v", outcomeNames[o], set[o], tt.want[o])
+				}
+			}
+		})
+	}
+
+	if _, err := newSoundSet(true, map[outcome]string{outcomeSuccess: "loud"}); err == nil {
+		t.Error("newSoundSet() with a bad override returned no error")
+	}
+}
+
+func TestDefaultSoundsDistinct(t *testing.T) {
+	seen := map[string]outcome{}
+	for o, pattern := range defaultSounds {
+		if other, ok := seen[pattern]; ok {
+			t.Errorf("%s and %s share the sound %q", outcomeNames[o], outcomeNames[other], pattern)
+		}
+		seen[pattern] = o
+	}
+	if len(defaultSounds) != len(outcomeNames) {
+		t.Errorf("%d default sounds for %d outcomes", len(defaultSounds), len(outcomeNames))
+	}
+}
+
+func TestSoundSetString(t *testing.T) {
+	set := soundSet{outcomeSuccess: &soundSpec{WAV: "a.wav"}, outcomeError: &soundSpec{WAV: "b.wav"}}
+	if got, want := set.String(), "success, error"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"maple_flame/internal/automation"
+)
+
+// releaseModifiers lets go of any modifier key left held at the end of a
+// session; tests replace it to see when it runs
+var releaseModifiers = automation.ReleaseModifiers
+
+// stopRequest is a request to end the session made from outside the loop,
+// by Ctrl+C or the watchdog. The loop checks it between the phases of an
+// attempt and leaves through its normal cleanup.
+type stopRequest struct {
+	mu     sync.Mutex
+	reason string
+}
+
+// Request asks the loop to stop; the first reason given is kept
+func (s *stopRequest) Request(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reason == "" {
+		s.reason = reason
+	}
+}
+
+// Requested returns the reason a stop was requested, and whether one was
+func (s *stopRequest) Requested() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason, s.reason != ""
+}
+
+// watchInterrupts turns the first Ctrl+C into a stop request. The handler is
+// then removed, so a second Ctrl+C kills the process the default way even if
+// the loop is stuck in a capture or a tesseract run. The returned function
+// stops watching.
+func watchInterrupts(stop *stopRequest) func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-interrupts:
+		case <-done:
+			return
+		}
+		signal.Stop(interrupts)
+		stop.Request("Ctrl+C pressed")
+		fmt.Println("\n🛑 Ctrl+C pressed - stopping after this step (press again to force quit)")
+	}()
+
+	return func() {
+		close(done)
+		signal.Stop(interrupts)
+	}
+}
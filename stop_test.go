@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what run prints to stdout
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		output <- b.String()
+	}()
+
+	run()
+	w.Close()
+	return <-output
+}
+
+func TestShutdownOrder(t *testing.T) {
+	defer func(release func()) { releaseModifiers = release }(releaseModifiers)
+	releaseModifiers = func() { fmt.Println("[modifiers released]") }
+
+	const text = "STR +6%\nDEX +9%\n"
+
+	tests := []struct {
+		name      string
+		stopAfter int // reads before Ctrl+C is pressed (0 = before the loop starts)
+	}{
+		{"before the first attempt", 0},
+		{"during an attempt", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop := &stopRequest{}
+			if tt.stopAfter == 0 {
+				stop.Request("Ctrl+C pressed")
+			}
+			cfg := &Config{Stop: stop}
+
+			var reads int
+			output := captureStdout(t, func() {
+				reads = runScriptedLoopWith(t, cfg, func(n int) string {
+					if n+1 == tt.stopAfter {
+						stop.Request("Ctrl+C pressed")
+					}
+					return text
+				})
+			})
+			if reads != tt.stopAfter {
+				t.Errorf("loop read %d frames, want it to stop after %d", reads, tt.stopAfter)
+			}
+
+			order := []string{"Ctrl+C pressed - stopping gracefully", "[modifiers released]", "Session summary"}
+			last := -1
+			for _, step := range order {
+				at := strings.Index(output, step)
+				if at < 0 {
+					t.Fatalf("output is missing %q:\n%s", step, output)
+				}
+				if at < last {
+					t.Errorf("%q came out of order:\n%s", step, output)
+				}
+				last = at
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// stopExpr is a compiled --stop-expr. Expressions are small boolean formulas
+// over stat names, e.g. "STR >= 30 || ALLSTAT >= 6 || (STR + ALLSTAT*10 >= 90 && BOSS > 0)".
+//
+// Grammar (keywords and stat names are case-insensitive):
+//
+//	or      = and { ("||" | "or") and }
+//	and     = not { ("&&" | "and") not }
+//	not     = ("!" | "not") not | compare
+//	compare = sum [ (">=" | "<=" | ">" | "<" | "==" | "!=") sum ]
+//	sum     = product { ("+" | "-") product }
+//	product = factor { "*" factor }
+//	factor  = "-" factor | number ["%"] | stat | "(" or ")"
+//
+// Booleans are 1 and 0, so a bare stat name means "stat is non-zero".
+// Nothing but arithmetic and comparisons is evaluated.
+type stopExpr struct {
+	source string
+	eval   func(vars map[string]float64) float64
+}
+
+// stopExprVars are the names an expression may refer to
+var stopExprVars = map[string]bool{
+	"STR": true, "DEX": true, "INT": true, "LUK": true, "ALLSTAT": true, "ALLSTATFLAT": true,
+	"ATT": true, "MATT": true, "BOSS": true, "IED": true, "DAMAGE": true,
+	"HP": true, "MP": true, "SCORE": true,
+}
+
+// parseStopExpr compiles an expression, rejecting unknown names and syntax errors
+func parseStopExpr(source string) (*stopExpr, error) {
+	tokens, err := tokenizeStopExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty stop expression")
+	}
+
+	p := &stopExprParser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in stop expression", p.tokens[p.pos])
+	}
+	return &stopExpr{source: source, eval: eval}, nil
+}
+
+// Matches evaluates the expression against a set of stat values. Missing
+// stats count as 0.
+func (e *stopExpr) Matches(vars map[string]float64) bool {
+	return e.eval(vars) != 0
+}
+
+// String returns the expression as it was written
+func (e *stopExpr) String() string {
+	return e.source
+}
+
+// tokenizeStopExpr splits an expression into numbers, names and operators
+func tokenizeStopExpr(source string) ([]string, error) {
+	var tokens []string
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+			// "12%" reads the same as the stat line it refers to
+			if i < len(runes) && runes[i] == '%' {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, strings.ToUpper(string(runes[start:i])))
+		default:
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				switch pair {
+				case ">=", "<=", "==", "!=", "&&", "||":
+					tokens = append(tokens, pair)
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("<>!+-*()", r) {
+				return nil, fmt.Errorf("unexpected character %q in stop expression", r)
+			}
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// stopExprParser is a recursive descent parser that builds evaluation closures
+type stopExprParser struct {
+	tokens []string
+	pos    int
+}
+
+type stopExprFunc = func(vars map[string]float64) float64
+
+// peek returns the next token, or "" at the end of the input
+func (p *stopExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// accept consumes the next token if it is one of the given alternatives
+func (p *stopExprParser) accept(alternatives ...string) (string, bool) {
+	next := p.peek()
+	for _, alt := range alternatives {
+		if next == alt {
+			p.pos++
+			return next, true
+		}
+	}
+	return "", false
+}
+
+func (p *stopExprParser) parseOr() (stopExprFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "OR"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) float64 {
+			return boolValue(l(vars) != 0 || right(vars) != 0)
+		}
+	}
+}
+
+func (p *stopExprParser) parseAnd() (stopExprFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "AND"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) float64 {
+			return boolValue(l(vars) != 0 && right(vars) != 0)
+		}
+	}
+}
+
+func (p *stopExprParser) parseNot() (stopExprFunc, error) {
+	if _, ok := p.accept("!", "NOT"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) float64 {
+			return boolValue(operand(vars) == 0)
+		}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *stopExprParser) parseCompare() (stopExprFunc, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept(">=", "<=", ">", "<", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return func(vars map[string]float64) float64 {
+		a, b := left(vars), right(vars)
+		switch op {
+		case ">=":
+			return boolValue(a >= b)
+		case "<=":
+			return boolValue(a <= b)
+		case ">":
+			return boolValue(a > b)
+		case "<":
+			return boolValue(a < b)
+		case "==":
+			return boolValue(a == b)
+		default:
+			return boolValue(a != b)
+		}
+	}, nil
+}
+
+func (p *stopExprParser) parseSum() (stopExprFunc, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(vars map[string]float64) float64 { return l(vars) + right(vars) }
+		} else {
+			left = func(vars map[string]float64) float64 { return l(vars) - right(vars) }
+		}
+	}
+}
+
+func (p *stopExprParser) parseProduct() (stopExprFunc, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("*"); !ok {
+			return left, nil
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(vars map[string]float64) float64 { return l(vars) * right(vars) }
+	}
+}
+
+func (p *stopExprParser) parseFactor() (stopExprFunc, error) {
+	token := p.peek()
+	if token == "" {
+		return nil, fmt.Errorf("stop expression ends unexpectedly")
+	}
+	p.pos++
+
+	if token == "-" {
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) float64 { return -operand(vars) }, nil
+	}
+
+	if token == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing ) in stop expression")
+		}
+		return inner, nil
+	}
+
+	if value, err := strconv.ParseFloat(token, 64); err == nil {
+		return func(map[string]float64) float64 { return value }, nil
+	}
+
+	if stopExprVars[token] {
+		return func(vars map[string]float64) float64 { return vars[token] }, nil
+	}
+
+	if unicode.IsLetter([]rune(token)[0]) {
+		return nil, fmt.Errorf("unknown name %q in stop expression", token)
+	}
+	return nil, fmt.Errorf("unexpected %q in stop expression", token)
+}
+
+// boolValue converts a comparison result into the 1/0 the evaluator works with
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
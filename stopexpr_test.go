@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestParseStopExpr(t *testing.T) {
+	tests := []struct {
+		source string
+		vars   map[string]float64
+		want   bool
+	}{
+		{"STR >= 30", map[string]float64{"STR": 30}, true},
+		{"STR >= 30", map[string]float64{"STR": 29}, false},
+		{"str >= 30 or allstat >= 6", map[string]float64{"ALLSTAT": 6}, true},
+		{"STR >= 12% && BOSS > 0", map[string]float64{"STR": 12}, false},
+		{"STR + ALLSTAT*10 >= 90", map[string]float64{"STR": 30, "ALLSTAT": 6}, true},
+		{"(STR >= 30 || DEX >= 30) && !BOSS", map[string]float64{"DEX": 31}, true},
+		{"not (STR >= 30)", map[string]float64{"STR": 31}, false},
+		{"-HP < 0", map[string]float64{"HP": 3}, true},
+		{"SCORE == 2", map[string]float64{"SCORE": 2}, true},
+		{"IED != 0", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := parseStopExpr(tt.source)
+			if err != nil {
+				t.Fatalf("parseStopExpr(%q) error: %v", tt.source, err)
+			}
+			if got := expr.Matches(tt.vars); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.vars, got, tt.want)
+			}
+			if expr.String() != tt.source {
+				t.Errorf("String() = %q, want %q", expr.String(), tt.source)
+			}
+		})
+	}
+}
+
+func TestParseStopExprErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"FOO >= 1",
+		"STR >=",
+		"(STR >= 30",
+		"STR >= 30)",
+		"STR = 30",
+		"STR >= 30 $",
+	}
+
+	for _, source := range tests {
+		if _, err := parseStopExpr(source); err == nil {
+			t.Errorf("parseStopExpr(%q) succeeded, want an error", source)
+		}
+	}
+}
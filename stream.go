@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// resultStream writes attempt results as JSON lines, flushing after every line
+// so a consuming process (e.g. an overlay) sees each result immediately
+type resultStream struct {
+	mu     sync.Mutex
+	buf    *bufio.Writer
+	closer io.Closer
+}
+
+// openResultStream opens the stream target: "-" for the given stdout, or a
+// named pipe (\\.\pipe\name) or file path
+func openResultStream(target string, stdout io.Writer) (*resultStream, error) {
+	if target == "-" {
+		return newResultStream(stdout, nil), nil
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream %s: %v", target, err)
+	}
+	return newResultStream(f, f), nil
+}
+
+// newResultStream wraps a writer; closer may be nil when the writer isn't owned
+func newResultStream(w io.Writer, closer io.Closer) *resultStream {
+	return &resultStream{buf: bufio.NewWriter(w), closer: closer}
+}
+
+// Write emits one record as a JSON line. A nil stream does nothing.
+func (s *resultStream) Write(record attemptResult) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := json.NewEncoder(s.buf).Encode(record); err != nil {
+		return err
+	}
+	return s.buf.Flush()
+}
+
+// Close flushes the stream and closes the target if it is owned
+func (s *resultStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"maple_flame/internal/automation"
+)
+
+// takeoverPollInterval is how often a held takeover key is checked for release
+const takeoverPollInterval = 100 * time.Millisecond
+
+// waitWhileHeld blocks while the takeover key is held so the user can use the
+// mouse and keyboard without the loop capturing or clicking. beat is called
+// while waiting so a long takeover doesn't look like a stall. It reports
+// whether the stop key was pressed or a stop requested during the pause.
+func waitWhileHeld(key *automation.HoldKey, clock Clock, beat func(), stop *stopRequest) bool {
+	if key == nil || !key.Held() {
+		return false
+	}
+
+	fmt.Printf("✋ %s held - paused, release it to resume\n", key.Name())
+	for key.Held() {
+		if automation.CheckStopKey() {
+			return true
+		}
+		if _, ok := stop.Requested(); ok {
+			return true
+		}
+		beat()
+		clock.Sleep(takeoverPollInterval)
+	}
+	fmt.Println("▶️ Released - resuming")
+	return false
+}
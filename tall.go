@@ -0,0 +1,43 @@
+package main
+
+import (
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// tallPanelOverlap is how many rows the lower --tall-panel capture shares
+// with the main one, so a line on the seam is read whole by at least one
+const tallPanelOverlap = 24
+
+// tallPanelOverlapLines is how many stat lines fit in tallPanelOverlap rows.
+// The overlap is shorter than two lines, so at most one is read in both.
+const tallPanelOverlapLines = 1
+
+// lowerPanelRegion is the capture-sized region just below the main capture,
+// overlapping it by tallPanelOverlap rows
+func lowerPanelRegion(windowRect *window.WindowRect) screenshot.Region {
+	region := statRegion(windowRect)
+	region.Y += region.Height - tallPanelOverlap
+	return region
+}
+
+// readLowerPanel captures and OCRs the region below the main capture, for
+// items with more stat lines than fit in it
+func readLowerPanel(handle *window.WindowHandle, cfg *Config) (string, error) {
+	img, err := screenshot.CaptureHandleRegion(handle, lowerPanelRegion(handle.Bounds()))
+	if err != nil {
+		return "", err
+	}
+	img = screenshot.Crop(img, screenshot.Margins{Left: cfg.CropMargins.Left, Right: cfg.CropMargins.Right, Bottom: cfg.CropMargins.Bottom})
+	if cfg.Invert == invertOn {
+		img = screenshot.InvertImage(img)
+	}
+
+	filename, err := screenshot.SaveDebugImageWithPrefix(img, "lower", 1)
+	if err != nil {
+		return "", err
+	}
+
+	text, _, err := ocrFile(filename, cfg)
+	return text, err
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"maple_flame/internal/screenshot"
+	"maple_flame/internal/window"
+)
+
+// verifyFrameDelay is the pause before each confirming capture, so the
+// frames aren't all taken mid-way through the same redraw
+const verifyFrameDelay = 300 * time.Millisecond
+
+// confirmSuccess re-reads the stats frames more times and reports whether
+// every read still meets the stop condition. A read that fails or disagrees
+// means the original success was most likely an OCR misread; reason says
+// which frame disagreed. read and sleep are injectable so this can run
+// without a game window.
+func confirmSuccess(frames int, read func(frame int) (string, error), stops func(text string) bool, sleep func(time.Duration)) (bool, string) {
+	for frame := 1; frame <= frames; frame++ {
+		sleep(verifyFrameDelay)
+
+		text, err := read(frame)
+		if err != nil {
+			return false, fmt.Sprintf("frame %d/%d could not be read: %v", frame, frames, err)
+		}
+		if !stops(text) {
+			return false, fmt.Sprintf("frame %d/%d does not meet the stop condition", frame, frames)
+		}
+	}
+	return true, ""
+}
+
+// readVerifyFrame captures and OCRs the stat box again the same way the loop
+// does, for confirmSuccess
+func readVerifyFrame(handle *window.WindowHandle, cfg *Config, frame int) (string, error) {
+	img, err := screenshot.CaptureHandleRegion(handle, statRegion(handle.Bounds()))
+	if err != nil {
+		return "", err
+	}
+	read, err := readStats(screenshot.Crop(img, cfg.CropMargins), handle, cfg, "verify", frame)
+	if err != nil {
+		return "", err
+	}
+	if !confidentRead(read.Confidence, cfg.MinConfidence) {
+		return "", fmt.Errorf("OCR confidence %.0f is below %.0f", read.Confidence, cfg.MinConfidence)
+	}
+	return read.Text, nil
+}
+
+// confidentRead reports whether a read's OCR confidence is high enough to
+// act on; a minimum of 0 accepts every read
+func confidentRead(confidence, minimum float64) bool {
+	return minimum <= 0 || confidence >= minimum
+}
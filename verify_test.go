@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfirmSuccess(t *testing.T) {
+	good := "STR +12%\nAll Stats +6%\n"
+	bad := "DEF +120\nMax HP +3%\n"
+
+	tests := []struct {
+		name       string
+		frames     int
+		reads      []string // "" reads as an OCR error
+		want       bool
+		wantReason string
+		wantReads  int
+	}{
+		{"no frames", 0, nil, true, "", 0},
+		{"all agree", 3, []string{good, good, good}, true, "", 3},
+		{"second disagrees", 3, []string{good, bad, good}, false, "frame 2/3 does not meet", 2},
+		{"read fails", 2, []string{"", good}, false, "frame 1/2 could not be read", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads := 0
+			read := func(frame int) (string, error) {
+				reads++
+				if text := tt.reads[frame-1]; text != "" {
+					return text, nil
+				}
+				return "", errors.New("tesseract failed")
+			}
+			stops := func(text string) bool {
+				return countMainStatLines(text, STR, 0) >= 2
+			}
+			var slept time.Duration
+			sleep := func(d time.Duration) { slept += d }
+
+			got, reason := confirmSuccess(tt.frames, read, stops, sleep)
+			if got != tt.want {
+				t.Errorf("confirmSuccess() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(reason, tt.wantReason) || (tt.wantReason == "" && reason != "") {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.wantReason)
+			}
+			if reads != tt.wantReads {
+				t.Errorf("read %d frames, want %d", reads, tt.wantReads)
+			}
+			if want := time.Duration(tt.wantReads) * verifyFrameDelay; slept != want {
+				t.Errorf("slept %s, want %s", slept, want)
+			}
+		})
+	}
+}
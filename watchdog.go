@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"maple_flame/internal/window"
+)
+
+// watchdog notices when the reroll loop stops completing attempts
+type watchdog struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	now     func() time.Time
+	last    time.Time
+}
+
+// newWatchdog creates a watchdog that considers the loop stalled after timeout
+// without a Beat. now is injectable so the timing can be driven by a fake clock.
+func newWatchdog(timeout time.Duration, now func() time.Time) *watchdog {
+	return &watchdog{timeout: timeout, now: now, last: now()}
+}
+
+// Beat records that an attempt just completed
+func (w *watchdog) Beat() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.last = w.now()
+}
+
+// Stalled returns how long it has been since the last beat, and whether that exceeds the timeout
+func (w *watchdog) Stalled() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idle := w.now().Sub(w.last)
+	return idle, idle >= w.timeout
+}
+
+// Watch checks for stalls on every tick until done is closed, calling onStall
+// with the idle time whenever the loop has stalled. Ticks pace the checks on
+// the wall clock even when now is a fake clock, whose Sleep returns at once
+// and would otherwise have this loop spin.
+func (w *watchdog) Watch(done <-chan struct{}, tick <-chan time.Time, onStall func(idle time.Duration)) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick:
+		}
+		if idle, stalled := w.Stalled(); stalled {
+			onStall(idle)
+		}
+	}
+}
+
+// startWatchdog runs a watchdog for the reroll loop when a timeout is configured.
+// With --watchdog-abort a stall requests a stop, so the loop still leaves
+// through its cleanup. Without it a stall only re-activates the game window:
+// that recovers a loop stuck behind an unfocused or covered window. Neither
+// can recover a loop blocked inside a call that never returns (a hung
+// capture or tesseract run), since the loop has to come back to see a stop;
+// that takes a second Ctrl+C. It returns a nil watchdog and a no-op stop
+// function when disabled.
+func startWatchdog(cfg *Config, stop *stopRequest, handle *window.WindowHandle) (*watchdog, func()) {
+	if cfg.WatchdogTimeout <= 0 {
+		return nil, func() {}
+	}
+
+	dog := newWatchdog(cfg.WatchdogTimeout, cfg.clock().Now)
+	done := make(chan struct{})
+	interval := cfg.WatchdogTimeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	go dog.Watch(done, ticker.C, func(idle time.Duration) {
+		if cfg.WatchdogAbort {
+			if _, requested := stop.Requested(); !requested {
+				fmt.Printf("\n🐕 WATCHDOG: no attempt completed in %s\n", idle.Round(time.Second))
+				stop.Request("Watchdog abort")
+			}
+			return
+		}
+
+		fmt.Printf("\n🐕 WATCHDOG: no attempt completed in %s\n", idle.Round(time.Second))
+
+		// This only helps a loop that is still running, e.g. waiting on a
+		// window that lost focus; it can't unblock a hung call
+		fmt.Println("Re-activating MapleStory and continuing...")
+		if handle != nil {
+			if err := handle.Activate(); err != nil {
+				fmt.Printf("⚠️ Could not activate MapleStory: %v\n", err)
+			}
+		}
+		dog.Beat()
+	})
+
+	return dog, func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdogStalled(t *testing.T) {
+	const timeout = 10 * time.Second
+	clock := newFakeClock()
+	dog := newWatchdog(timeout, clock.Now)
+
+	steps := []struct {
+		advance time.Duration
+		beat    bool
+		want    bool
+	}{
+		{timeout - time.Second, false, false},
+		{time.Second, false, true},
+		{time.Second, true, false},
+		{timeout - time.Second, false, false},
+		{time.Second, false, true},
+	}
+
+	for i, step := range steps {
+		clock.Sleep(step.advance)
+		if step.beat {
+			dog.Beat()
+		}
+		if idle, stalled := dog.Stalled(); stalled != step.want {
+			t.Errorf("step %d: Stalled() = %s, %v, want stalled %v", i, idle, stalled, step.want)
+		}
+	}
+}
+
+func TestWatchdogWatch(t *testing.T) {
+	const timeout = 10 * time.Second
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The times now returns, in call order: the start, then one per check
+	// and one per Beat after a stall. Scripting them keeps the checks in
+	// step with the clock however the goroutines are scheduled.
+	times := []time.Duration{0, 9 * time.Second, 10 * time.Second, 10 * time.Second, 15 * time.Second, 21 * time.Second, 21 * time.Second}
+	calls := 0
+	now := func() time.Time {
+		at := times[min(calls, len(times)-1)]
+		calls++
+		return base.Add(at)
+	}
+	dog := newWatchdog(timeout, now)
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	var stalls []time.Duration
+	watching := make(chan struct{})
+	go func() {
+		defer close(watching)
+		dog.Watch(done, tick, func(idle time.Duration) {
+			stalls = append(stalls, idle)
+			dog.Beat()
+		})
+	}()
+
+	for i := 0; i < 4; i++ {
+		tick <- base
+	}
+	close(done)
+	<-watching
+
+	want := []time.Duration{timeout, 11 * time.Second}
+	if len(stalls) != len(want) {
+		t.Fatalf("stalls = %v, want %v", stalls, want)
+	}
+	for i := range want {
+		if stalls[i] != want[i] {
+			t.Errorf("stall %d idle = %s, want %s", i, stalls[i], want[i])
+		}
+	}
+	if calls != len(times) {
+		t.Errorf("now called %d times, want %d", calls, len(times))
+	}
+}